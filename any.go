@@ -0,0 +1,402 @@
+package jx
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+// AnyType is type of Any value.
+type AnyType byte
+
+// Possible types for Any.
+const (
+	AnyInvalid AnyType = iota
+	AnyStr
+	AnyNumber
+	AnyNull
+	AnyObj
+	AnyArr
+	AnyBool
+)
+
+func (t AnyType) String() string {
+	switch t {
+	case AnyStr:
+		return "string"
+	case AnyNumber:
+		return "number"
+	case AnyNull:
+		return "null"
+	case AnyObj:
+		return "object"
+	case AnyArr:
+		return "array"
+	case AnyBool:
+		return "bool"
+	default:
+		return "invalid"
+	}
+}
+
+// Any represents any json value as sum type.
+//
+// Object fields are stored as Child elements with KeyValid set, preserving
+// the order of keys as they were decoded or added.
+type Any struct {
+	Type AnyType // zero value if AnyInvalid, can be AnyNull
+
+	Str    string // AnyStr
+	Bool   bool   // AnyBool
+	Number Num    // AnyNumber
+
+	// Key in object. Valid only if KeyValid.
+	Key string
+	// KeyValid denotes whether Any is element of object.
+	// Needed for representing Key that is blank.
+	//
+	// Can be true only for Child of AnyObj.
+	KeyValid bool
+
+	Child []Any // AnyArr or AnyObj
+}
+
+// Equal reports whether v is equal to b.
+//
+// Objects are equal only if keys are in the same order, numbers are
+// compared with Num.Equal.
+func (v Any) Equal(b Any) bool {
+	if v.KeyValid != b.KeyValid || v.KeyValid && v.Key != b.Key {
+		return false
+	}
+	if v.Type != b.Type {
+		return false
+	}
+	switch v.Type {
+	case AnyNull, AnyInvalid:
+		return true
+	case AnyBool:
+		return v.Bool == b.Bool
+	case AnyStr:
+		return v.Str == b.Str
+	case AnyNumber:
+		return v.Number.Equal(b.Number)
+	}
+	if len(v.Child) != len(b.Child) {
+		return false
+	}
+	for i := range v.Child {
+		if !v.Child[i].Equal(b.Child[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Any reads Any value.
+func (d *Decoder) Any() (Any, error) {
+	var v Any
+	if err := v.Read(d); err != nil {
+		return Any{}, err
+	}
+	return v, nil
+}
+
+// Any encodes Any value.
+func (e *Encoder) Any(a Any) bool {
+	return a.Write(e)
+}
+
+// Read reads json value from Decoder into v.
+func (v *Any) Read(d *Decoder) error {
+	switch d.Next() {
+	case Invalid:
+		// Skip reports error of Next with offset.
+		err := d.Skip()
+		if err == nil {
+			err = errors.New("unexpected value")
+		}
+		return d.decodeErr(errors.Wrap(err, "invalid"))
+	case Number:
+		n, err := d.NumAppend(nil)
+		if err != nil {
+			return errors.Wrap(err, "number")
+		}
+		v.Number = n
+		v.Type = AnyNumber
+	case String:
+		s, err := d.Str()
+		if err != nil {
+			return errors.Wrap(err, "str")
+		}
+		v.Str = s
+		v.Type = AnyStr
+	case Null:
+		if err := d.Null(); err != nil {
+			return errors.Wrap(err, "null")
+		}
+		v.Type = AnyNull
+	case Bool:
+		b, err := d.Bool()
+		if err != nil {
+			return errors.Wrap(err, "bool")
+		}
+		v.Bool = b
+		v.Type = AnyBool
+	case Object:
+		v.Type = AnyObj
+		if err := d.Obj(func(r *Decoder, s string) error {
+			var elem Any
+			if err := elem.Read(r); err != nil {
+				return errors.Wrap(err, "elem")
+			}
+			elem.Key = s
+			elem.KeyValid = true
+			v.Child = append(v.Child, elem)
+			return nil
+		}); err != nil {
+			return errors.Wrap(err, "obj")
+		}
+		return nil
+	case Array:
+		v.Type = AnyArr
+		if err := d.Arr(func(r *Decoder) error {
+			var elem Any
+			if err := elem.Read(r); err != nil {
+				return errors.Wrap(err, "elem")
+			}
+			v.Child = append(v.Child, elem)
+			return nil
+		}); err != nil {
+			return errors.Wrap(err, "array")
+		}
+		return nil
+	}
+	return nil
+}
+
+// Write json representation of Any to Encoder.
+func (v Any) Write(w *Encoder) (fail bool) {
+	if v.KeyValid {
+		fail = w.FieldStart(v.Key)
+	}
+	switch v.Type {
+	case AnyStr:
+		fail = fail || w.Str(v.Str)
+	case AnyNumber:
+		fail = fail || w.Num(v.Number)
+	case AnyBool:
+		fail = fail || w.Bool(v.Bool)
+	case AnyNull:
+		fail = fail || w.Null()
+	case AnyArr:
+		fail = fail || w.ArrStart()
+		for _, c := range v.Child {
+			fail = fail || c.Write(w)
+		}
+		fail = fail || w.ArrEnd()
+	case AnyObj:
+		fail = fail || w.ObjStart()
+		for _, c := range v.Child {
+			fail = fail || c.Write(w)
+		}
+		fail = fail || w.ObjEnd()
+	}
+	return fail
+}
+
+func (v Any) String() string {
+	var b strings.Builder
+	if v.KeyValid {
+		if v.Key == "" {
+			b.WriteString("<blank>")
+		}
+		b.WriteString(v.Key)
+		b.WriteString(": ")
+	}
+	switch v.Type {
+	case AnyStr:
+		b.WriteString(`'` + v.Str + `'`)
+	case AnyNumber:
+		b.WriteString(v.Number.String())
+	case AnyBool:
+		b.WriteString(strconv.FormatBool(v.Bool))
+	case AnyNull:
+		b.WriteString("null")
+	case AnyArr:
+		b.WriteString("[")
+		for i, c := range v.Child {
+			if i != 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(c.String())
+		}
+		b.WriteString("]")
+	case AnyObj:
+		b.WriteString("{")
+		for i, c := range v.Child {
+			if i != 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(c.String())
+		}
+		b.WriteString("}")
+	default:
+		b.WriteString("<invalid>")
+	}
+	return b.String()
+}
+
+// Reset Any value to reuse.
+func (v *Any) Reset() {
+	v.Type = AnyInvalid
+	v.Child = v.Child[:0]
+	v.KeyValid = false
+
+	v.Str = ""
+	v.Key = ""
+	v.Bool = false
+	v.Number = v.Number[:0]
+}
+
+// Obj calls f for any child that is field if v is AnyObj.
+func (v Any) Obj(f func(k string, v Any)) {
+	if v.Type != AnyObj {
+		return
+	}
+	for _, c := range v.Child {
+		if !c.KeyValid {
+			continue
+		}
+		f(c.Key, c)
+	}
+}
+
+// Arr calls f for every element if v is AnyArr.
+func (v Any) Arr(f func(i int, v Any)) {
+	if v.Type != AnyArr {
+		return
+	}
+	for i, c := range v.Child {
+		f(i, c)
+	}
+}
+
+// Len returns count of elements or fields if v is AnyArr or AnyObj.
+//
+// Returns zero for other types.
+func (v Any) Len() int {
+	switch v.Type {
+	case AnyArr, AnyObj:
+		return len(v.Child)
+	default:
+		return 0
+	}
+}
+
+func (v Any) field(key string) int {
+	if v.Type != AnyObj {
+		return -1
+	}
+	for i, c := range v.Child {
+		if c.KeyValid && c.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns value of field with given key if v is AnyObj.
+func (v Any) Get(key string) (Any, bool) {
+	idx := v.field(key)
+	if idx < 0 {
+		return Any{}, false
+	}
+	return v.Child[idx], true
+}
+
+// Index returns i-th element if v is AnyArr.
+func (v Any) Index(i int) (Any, bool) {
+	if v.Type != AnyArr || i < 0 || i >= len(v.Child) {
+		return Any{}, false
+	}
+	return v.Child[i], true
+}
+
+// Set sets value of field with given key, keeping position of existing
+// field or appending new one to the end.
+//
+// If v is AnyInvalid, it becomes an empty object first.
+func (v *Any) Set(key string, val Any) {
+	if v.Type == AnyInvalid {
+		v.Type = AnyObj
+	}
+	if v.Type != AnyObj {
+		return
+	}
+	val.Key = key
+	val.KeyValid = true
+	if idx := v.field(key); idx >= 0 {
+		v.Child[idx] = val
+		return
+	}
+	v.Child = append(v.Child, val)
+}
+
+// Delete removes field with given key, returning true if it was present.
+func (v *Any) Delete(key string) bool {
+	idx := v.field(key)
+	if idx < 0 {
+		return false
+	}
+	v.Child = v.without(idx)
+	return true
+}
+
+// Append appends elements to array.
+//
+// If v is AnyInvalid, it becomes an empty array first.
+func (v *Any) Append(vals ...Any) {
+	if v.Type == AnyInvalid {
+		v.Type = AnyArr
+	}
+	if v.Type != AnyArr {
+		return
+	}
+	for _, val := range vals {
+		val.Key = ""
+		val.KeyValid = false
+		v.Child = append(v.Child, val)
+	}
+}
+
+// SetIndex replaces i-th element of array, returning false if i is out of
+// range or v is not AnyArr.
+func (v *Any) SetIndex(i int, val Any) bool {
+	if v.Type != AnyArr || i < 0 || i >= len(v.Child) {
+		return false
+	}
+	val.Key = ""
+	val.KeyValid = false
+	v.Child[i] = val
+	return true
+}
+
+// RemoveIndex removes i-th element of array, returning false if i is out of
+// range or v is not AnyArr.
+func (v *Any) RemoveIndex(i int) bool {
+	if v.Type != AnyArr || i < 0 || i >= len(v.Child) {
+		return false
+	}
+	v.Child = v.without(i)
+	return true
+}
+
+// without returns copy of children without i-th one, so that values
+// sharing children with v are not changed.
+func (v *Any) without(i int) []Any {
+	child := make([]Any, 0, len(v.Child)-1)
+	child = append(child, v.Child[:i]...)
+	return append(child, v.Child[i+1:]...)
+}
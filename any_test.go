@@ -3,250 +3,14 @@ package jx
 import (
 	hexEnc "encoding/hex"
 	"encoding/json"
+	"io"
 	"strconv"
-	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-
-	"github.com/go-faster/errors"
-)
-
-// NB: Any left intentionally unexported
-
-// AnyType is type of Any value.
-type AnyType byte
-
-// Possible types for Any.
-const (
-	AnyInvalid AnyType = iota
-	AnyStr
-	AnyNumber
-	AnyNull
-	AnyObj
-	AnyArr
-	AnyBool
 )
 
-// Any represents any json value as sum type.
-type Any struct {
-	Type AnyType // zero value if AnyInvalid, can be AnyNull
-
-	Str    string // AnyStr
-	Bool   bool   // AnyBool
-	Number Num    // AnyNumber
-
-	// Key in object. Valid only if KeyValid.
-	Key string
-	// KeyValid denotes whether Any is element of object.
-	// Needed for representing Key that is blank.
-	//
-	// Can be true only for Child of AnyObj.
-	KeyValid bool
-
-	Child []Any // AnyArr or AnyObj
-}
-
-// Equal reports whether v is equal to b.
-func (v Any) Equal(b Any) bool {
-	if v.KeyValid && v.Key != b.Key {
-		return false
-	}
-	if v.Type != b.Type {
-		return false
-	}
-	switch v.Type {
-	case AnyNull, AnyInvalid:
-		return true
-	case AnyBool:
-		return v.Bool == b.Bool
-	case AnyStr:
-		return v.Str == b.Str
-	case AnyNumber:
-		return v.Number.Equal(b.Number)
-	}
-	if len(v.Child) != len(b.Child) {
-		return false
-	}
-	for i := range v.Child {
-		if !v.Child[i].Equal(b.Child[i]) {
-			return false
-		}
-	}
-	return true
-}
-
-// Any reads Any value.
-func (d *Decoder) Any() (Any, error) {
-	var v Any
-	if err := v.Read(d); err != nil {
-		return Any{}, err
-	}
-	return v, nil
-}
-
-// Any encodes Any value.
-func (e *Encoder) Any(a Any) {
-	a.Write(e)
-}
-
-func (v *Any) Read(d *Decoder) error {
-	switch d.Next() {
-	case Invalid:
-		return errors.New("invalid")
-	case Number:
-		n, err := d.NumAppend(nil)
-		if err != nil {
-			return errors.Wrap(err, "number")
-		}
-		v.Number = n
-		v.Type = AnyNumber
-	case String:
-		s, err := d.Str()
-		if err != nil {
-			return errors.Wrap(err, "str")
-		}
-		v.Str = s
-		v.Type = AnyStr
-	case Null:
-		if err := d.Null(); err != nil {
-			return errors.Wrap(err, "null")
-		}
-		v.Type = AnyNull
-	case Bool:
-		b, err := d.Bool()
-		if err != nil {
-			return errors.Wrap(err, "bool")
-		}
-		v.Bool = b
-		v.Type = AnyBool
-	case Object:
-		v.Type = AnyObj
-		if err := d.Obj(func(r *Decoder, s string) error {
-			var elem Any
-			if err := elem.Read(r); err != nil {
-				return errors.Wrap(err, "elem")
-			}
-			elem.Key = s
-			elem.KeyValid = true
-			v.Child = append(v.Child, elem)
-			return nil
-		}); err != nil {
-			return errors.Wrap(err, "obj")
-		}
-		return nil
-	case Array:
-		v.Type = AnyArr
-		if err := d.Arr(func(r *Decoder) error {
-			var elem Any
-			if err := elem.Read(r); err != nil {
-				return errors.Wrap(err, "elem")
-			}
-			v.Child = append(v.Child, elem)
-			return nil
-		}); err != nil {
-			return errors.Wrap(err, "array")
-		}
-		return nil
-	}
-	return nil
-}
-
-// Write json representation of Any to Encoder.
-func (v Any) Write(w *Encoder) {
-	if v.KeyValid {
-		w.FieldStart(v.Key)
-	}
-	switch v.Type {
-	case AnyStr:
-		w.Str(v.Str)
-	case AnyNumber:
-		w.Num(v.Number)
-	case AnyBool:
-		w.Bool(v.Bool)
-	case AnyNull:
-		w.Null()
-	case AnyArr:
-		w.ArrStart()
-		for _, c := range v.Child {
-			c.Write(w)
-		}
-		w.ArrEnd()
-	case AnyObj:
-		w.ObjStart()
-		for _, c := range v.Child {
-			c.Write(w)
-		}
-		w.ObjEnd()
-	}
-}
-
-func (v Any) String() string {
-	var b strings.Builder
-	if v.KeyValid {
-		if v.Key == "" {
-			b.WriteString("<blank>")
-		}
-		b.WriteString(v.Key)
-		b.WriteString(": ")
-	}
-	switch v.Type {
-	case AnyStr:
-		b.WriteString(`'` + v.Str + `'`)
-	case AnyNumber:
-		b.WriteString(v.Number.String())
-	case AnyBool:
-		b.WriteString(strconv.FormatBool(v.Bool))
-	case AnyNull:
-		b.WriteString("null")
-	case AnyArr:
-		b.WriteString("[")
-		for i, c := range v.Child {
-			if i != 0 {
-				b.WriteString(", ")
-			}
-			b.WriteString(c.String())
-		}
-		b.WriteString("]")
-	case AnyObj:
-		b.WriteString("{")
-		for i, c := range v.Child {
-			if i != 0 {
-				b.WriteString(", ")
-			}
-			b.WriteString(c.String())
-		}
-		b.WriteString("}")
-	default:
-		b.WriteString("<invalid>")
-	}
-	return b.String()
-}
-
-// Reset Any value to reuse.
-func (v *Any) Reset() {
-	v.Type = AnyInvalid
-	v.Child = v.Child[:0]
-	v.KeyValid = false
-
-	v.Str = ""
-	v.Key = ""
-}
-
-// Obj calls f for any child that is field if v is AnyObj.
-func (v Any) Obj(f func(k string, v Any)) {
-	if v.Type != AnyObj {
-		return
-	}
-	for _, c := range v.Child {
-		if !c.KeyValid {
-			continue
-		}
-		f(c.Key, c)
-	}
-}
-
 func TestAny_Read(t *testing.T) {
 	t.Run("Obj", func(t *testing.T) {
 		var v Any
@@ -310,6 +74,16 @@ func TestAny_Read(t *testing.T) {
 				require.Equal(t, "<invalid>", v.String())
 			})
 		}
+		t.Run("DecodeError", func(t *testing.T) {
+			_, err := DecodeStr(`{"a": [1, nil]}`).Any()
+			var e *DecodeError
+			require.ErrorAs(t, err, &e)
+			require.Equal(t, "/a/1", e.Pointer())
+			require.Equal(t, 11, e.Offset) // "i" of "nil"
+
+			_, err = DecodeStr(``).Any()
+			require.ErrorIs(t, err, io.EOF)
+		})
 		t.Run("Reader", func(t *testing.T) {
 			d := Decode(errReader{}, -1)
 			// Manually set internal buffer.
@@ -374,11 +148,107 @@ func TestAny_Equal(t *testing.T) {
 				require.True(t, a.Equal(b))
 				b.Key = "1"
 				require.False(t, a.Equal(b))
+
+				// Symmetric.
+				c := Any{Type: typ}
+				require.False(t, a.Equal(c))
+				require.False(t, c.Equal(a))
 			})
 		}
 	})
 }
 
+func TestAny_Mutate(t *testing.T) {
+	v, err := DecodeStr(`{"a":1,"b":[true],"c":"s"}`).Any()
+	require.NoError(t, err)
+	require.Equal(t, 3, v.Len())
+
+	b, ok := v.Get("b")
+	require.True(t, ok)
+	require.Equal(t, AnyArr, b.Type)
+	b.Append(Any{Type: AnyNull}, Any{Type: AnyNumber, Number: Num("2")})
+	require.True(t, b.SetIndex(0, Any{Type: AnyBool}))
+	require.False(t, b.SetIndex(5, Any{}))
+	require.True(t, b.RemoveIndex(1))
+	require.False(t, b.RemoveIndex(-1))
+	elem, ok := b.Index(1)
+	require.True(t, ok)
+	require.Equal(t, "2", elem.Number.String())
+	_, ok = b.Index(2)
+	require.False(t, ok)
+
+	v.Set("b", b)
+	v.Set("d", Any{Type: AnyStr, Str: "new"})
+	require.True(t, v.Delete("a"))
+	require.False(t, v.Delete("a"))
+	_, ok = v.Get("a")
+	require.False(t, ok)
+
+	var keys []string
+	v.Obj(func(k string, v Any) {
+		keys = append(keys, k)
+	})
+	require.Equal(t, []string{"b", "c", "d"}, keys)
+
+	e := GetEncoder()
+	defer PutEncoder(e)
+	require.False(t, e.Any(v))
+	require.Equal(t, `{"b":[false,2],"c":"s","d":"new"}`, e.String())
+
+	t.Run("Aliasing", func(t *testing.T) {
+		v, err := DecodeStr(`{"a":[1,2,3]}`).Any()
+		require.NoError(t, err)
+		orig, ok := v.Get("a")
+		require.True(t, ok)
+		arr := orig
+		require.True(t, arr.RemoveIndex(0))
+		require.True(t, v.Delete("a"))
+
+		require.Len(t, orig.Child, 3)
+		for i, c := range orig.Child {
+			require.Equal(t, strconv.Itoa(i+1), c.Number.String())
+		}
+	})
+	t.Run("Zero", func(t *testing.T) {
+		var obj Any
+		obj.Set("foo", Any{Type: AnyNull})
+		require.Equal(t, AnyObj, obj.Type)
+
+		var arr Any
+		arr.Append(Any{Type: AnyNull, Key: "ignored", KeyValid: true})
+		require.Equal(t, AnyArr, arr.Type)
+
+		e := GetEncoder()
+		defer PutEncoder(e)
+		e.ArrStart()
+		e.Any(obj)
+		e.Any(arr)
+		e.ArrEnd()
+		require.Equal(t, `[{"foo":null},[null]]`, e.String())
+	})
+	t.Run("WrongType", func(t *testing.T) {
+		v := Any{Type: AnyStr, Str: "foo"}
+		v.Set("foo", Any{})
+		v.Append(Any{})
+		require.Equal(t, 0, v.Len())
+		require.Empty(t, v.Child)
+
+		var n int
+		v.Arr(func(i int, v Any) { n++ })
+		v.Obj(func(k string, v Any) { n++ })
+		require.Zero(t, n)
+	})
+}
+
+func TestAnyType_String(t *testing.T) {
+	met := map[string]bool{}
+	for i := AnyInvalid; i <= AnyBool; i++ {
+		s := i.String()
+		require.False(t, met[s], "met %s", s)
+		met[s] = true
+	}
+}
+
 func BenchmarkAny(b *testing.B) {
 	buf := []byte(`[true, null, false, 100, "false"]`)
 	r := GetDecoder()
@@ -76,6 +76,8 @@ func init() {
 // Can decode from io.Reader or byte slice directly.
 type Decoder struct {
 	reader io.Reader
+	// at is reader of io.ReaderAt, also set as reader.
+	at *readerAt
 
 	// buf is current buffer.
 	//
@@ -87,6 +89,56 @@ type Decoder struct {
 
 	streamOffset int // for reader, offset in stream to start of current buf contents
 	depth        int
+
+	// streamLines is count of new lines before streamOffset,
+	// streamLineStart is offset of the line start before streamOffset and
+	// streamPrevLineStart is offset of the line start before it.
+	//
+	// Used to compute line and column for DecodeError.
+	streamLines         int
+	streamLineStart     int
+	streamPrevLineStart int
+	// path is stack of keys and indexes of objects and arrays being decoded.
+	path []pathFrame
+
+	limits Limits
+	utf8   UTF8Mode
+	// relaxed enables JSON5-style parsing.
+	relaxed bool
+
+	disallowDupKeys bool
+	// keys is stack of key sets of objects being decoded, used to detect
+	// duplicate keys.
+	keys []keySet
+	// keyGen is generation of last pushed key set.
+	keyGen uint64
+	// keyMarks is stack of key set states saved by active checkpoints and
+	// keysPinned is count of key sets that can be referenced by them, so
+	// their storage is not reused.
+	keyMarks   []keyMark
+	keysPinned int
+	// truncated is true if input is truncated by Limits.MaxBytes.
+	truncated bool
+
+	// tokens is stack of objects and arrays being read by Token.
+	tokens []tokenFrame
+	// tokenRaw and tokenStr are buffers reused by Token, tokenRaw is also
+	// reused by Compact and Indent.
+	tokenRaw rawReader
+	tokenStr []byte
+
+	// pins is stack of active checkpoints, input after first one is kept
+	// in buffer.
+	pins []pin
+	// checkpoints is count of created checkpoints, used as generation.
+	checkpoints uint64
+	// spare is buffer for read that keeps input of checkpoints.
+	spare []byte
+	// indexStop is absolute offset where structural index failed, values
+	// that start before it are not indexed.
+	indexStop int
+	// indexStack is reused stack of structural index.
+	indexStack []uint64
 }
 
 const defaultBuf = 512
@@ -122,9 +174,11 @@ func (d *Decoder) offset() int {
 // Reset resets reader and underlying state, next reads will use provided io.Reader.
 func (d *Decoder) Reset(reader io.Reader) {
 	d.reader = reader
+	d.at = nil
 	d.head = 0
 	d.tail = 0
 	d.depth = 0
+	d.resetStream()
 
 	// Reads from reader need buffer.
 	if cap(d.buf) == 0 {
@@ -140,9 +194,39 @@ func (d *Decoder) Reset(reader io.Reader) {
 // ResetBytes resets underlying state, next reads will use provided buffer.
 func (d *Decoder) ResetBytes(input []byte) {
 	d.reader = nil
+	d.at = nil
 	d.head = 0
 	d.tail = len(input)
 	d.depth = 0
+	d.resetStream()
 
 	d.buf = input
+	d.limitInput()
+}
+
+func (d *Decoder) resetStream() {
+	d.streamOffset = 0
+	d.streamLines = 0
+	d.streamLineStart = 0
+	d.streamPrevLineStart = 0
+	d.path = d.path[:0]
+	d.keys = d.keys[:0]
+	d.tokens = d.tokens[:0]
+	d.unpin()
+	d.truncated = false
+	d.indexStop = 0
+}
+
+// sub creates Decoder for buffer, inheriting depth and settings.
+func (d *Decoder) sub(buf []byte) Decoder {
+	return Decoder{
+		buf:     buf,
+		tail:    len(buf),
+		depth:   d.depth,
+		limits:  d.limits,
+		utf8:    d.utf8,
+		relaxed: d.relaxed,
+
+		disallowDupKeys: d.disallowDupKeys,
+	}
 }
@@ -27,14 +27,24 @@ func (d *Decoder) Elem() (ok bool, err error) {
 	case ']':
 		return false, nil
 	case ',':
-		return true, nil
+		end, err := d.trailingComma(']')
+		return !end, err
 	default:
 		return false, errors.Wrap(badToken(c, d.offset()), `"[", "," or "]" expected`)
 	}
 }
 
 // Arr decodes array and invokes callback on each array element.
+//
+// Returned error is *DecodeError, describing location of failure.
 func (d *Decoder) Arr(f func(d *Decoder) error) error {
+	if err := d.arr(f); err != nil {
+		return d.decodeErr(err)
+	}
+	return nil
+}
+
+func (d *Decoder) arr(f func(d *Decoder) error) error {
 	if err := d.consume('['); err != nil {
 		return errors.Wrap(err, `"[" expected`)
 	}
@@ -52,6 +62,7 @@ func (d *Decoder) Arr(f func(d *Decoder) error) error {
 		return d.decDepth()
 	}
 	d.unread()
+	frame := d.pushPath(false)
 	if err := f(d); err != nil {
 		return errors.Wrap(err, "callback")
 	}
@@ -61,6 +72,16 @@ func (d *Decoder) Arr(f func(d *Decoder) error) error {
 		return errors.Wrap(err, `"," or "]" expected`)
 	}
 	for c == ',' {
+		if end, err := d.trailingComma(']'); err != nil {
+			return err
+		} else if end {
+			c = ']'
+			break
+		}
+		d.path[frame].index++
+		if err := d.checkArrElems(d.path[frame].index + 1); err != nil {
+			return err
+		}
 		// Skip whitespace before reading element.
 		if _, err := d.next(); err != nil {
 			return err
@@ -77,5 +98,6 @@ func (d *Decoder) Arr(f func(d *Decoder) error) error {
 		err := badToken(c, d.offset()-1)
 		return errors.Wrap(err, `"]" expected`)
 	}
+	d.popPath(frame)
 	return d.decDepth()
 }
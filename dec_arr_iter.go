@@ -8,6 +8,7 @@ import (
 type ArrIter struct {
 	d      *Decoder
 	err    error
+	frame  int
 	closed bool
 	comma  bool
 }
@@ -15,16 +16,16 @@ type ArrIter struct {
 // ArrIter creates new array iterator.
 func (d *Decoder) ArrIter() (ArrIter, error) {
 	if err := d.consume('['); err != nil {
-		return ArrIter{}, errors.Wrap(err, `"[" expected`)
+		return ArrIter{}, d.decodeErr(errors.Wrap(err, `"[" expected`))
 	}
 	if err := d.incDepth(); err != nil {
-		return ArrIter{}, err
+		return ArrIter{}, d.decodeErr(err)
 	}
 	if _, err := d.more(); err != nil {
-		return ArrIter{}, err
+		return ArrIter{}, d.decodeErr(err)
 	}
 	d.unread()
-	return ArrIter{d: d}, nil
+	return ArrIter{d: d, frame: d.pushPath(false)}, nil
 }
 
 // Next consumes element and returns false, if there is no elements anymore.
@@ -32,32 +33,52 @@ func (i *ArrIter) Next() bool {
 	if i.closed || i.err != nil {
 		return false
 	}
+	if err := i.next(); err != nil {
+		i.err = i.d.decodeErr(err)
+		return false
+	}
+	return !i.closed
+}
 
+func (i *ArrIter) next() error {
 	dec := i.d
 	c, err := dec.more()
 	if err != nil {
-		i.err = err
-		return false
+		return err
 	}
 	if c == ']' {
-		i.closed = true
-		i.err = dec.decDepth()
-		return false
+		return i.close()
 	}
 	if i.comma {
 		if c != ',' {
 			err := badToken(c, dec.offset()-1)
-			i.err = errors.Wrap(err, `"," expected`)
-			return false
+			return errors.Wrap(err, `"," expected`)
+		}
+		if end, err := dec.trailingComma(']'); err != nil {
+			return err
+		} else if end {
+			return i.close()
+		}
+		dec.path[i.frame].index++
+		if err := dec.checkArrElems(dec.path[i.frame].index + 1); err != nil {
+			return err
 		}
 	} else {
 		dec.unread()
 	}
 	i.comma = true
-	return true
+	return nil
+}
+
+func (i *ArrIter) close() error {
+	i.closed = true
+	i.d.popPath(i.frame)
+	return i.d.decDepth()
 }
 
 // Err returns the error, if any, that was encountered during iteration.
+//
+// Error is *DecodeError, describing location of failure.
 func (i *ArrIter) Err() error {
 	return i.err
 }
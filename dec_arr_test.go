@@ -50,7 +50,7 @@ func TestDecoder_Arr(t *testing.T) {
 			data = append(data, '[')
 		}
 		d := DecodeBytes(data)
-		require.ErrorIs(t, d.Arr(nil), errMaxDepth)
+		require.ErrorIs(t, d.Arr(nil), ErrMaxDepth)
 	})
 }
 
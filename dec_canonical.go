@@ -0,0 +1,323 @@
+package jx
+
+import (
+	"io"
+	"math"
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/go-faster/errors"
+)
+
+// Canonical reads next value and writes its canonical form to w, as
+// defined by RFC 8785 (JSON Canonicalization Scheme):
+//
+//   - no insignificant whitespace
+//   - object keys are sorted by UTF-16 code units
+//   - numbers are serialized like ES6 Number.prototype.toString
+//   - strings are written with minimal escaping
+//
+// Input strings must be valid UTF-8, objects must not have duplicate keys
+// and numbers must fit into float64, otherwise error is returned.
+func (d *Decoder) Canonical(w *Writer) error {
+	mode := d.utf8
+	d.utf8 = UTF8Strict
+	defer func() {
+		d.utf8 = mode
+	}()
+
+	c := canonicalizer{}
+	return d.decodeErr(c.value(d, w))
+}
+
+// Canonical reads single json value from src and writes its canonical form
+// to dst. See Decoder.Canonical.
+func Canonical(dst io.Writer, src io.Reader) error {
+	return reformat(dst, src, func(d *Decoder, w *Writer) error {
+		return d.Canonical(w)
+	})
+}
+
+// canonicalizer writes canonical json.
+type canonicalizer struct {
+	// tmp holds members of objects being sorted, each member is decoded key
+	// followed by canonical form of member.
+	tmp     Writer
+	members []canonicalMember
+	str     []byte // buffer for string values
+}
+
+// canonicalMember is span of object member in canonicalizer.tmp.
+type canonicalMember struct {
+	keyStart int
+	keyEnd   int // also start of member
+	end      int
+	offset   int // offset of key in input
+}
+
+func (c *canonicalizer) fail(w *Writer) error {
+	if err := w.Err(); err != nil {
+		return err
+	}
+	return errors.New("write failed")
+}
+
+func (c *canonicalizer) value(d *Decoder, w *Writer) error {
+	switch d.Next() {
+	case String:
+		v, err := d.str(value{buf: c.str[:0]})
+		if err != nil {
+			return err
+		}
+		c.str = v.buf
+		if w.canonicalStr(v.buf) {
+			return c.fail(w)
+		}
+		return nil
+	case Number:
+		v, err := d.Float64()
+		if err != nil {
+			return err
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return errors.Errorf("invalid number %v", v)
+		}
+		if v == 0 {
+			// Negative zero is written as 0.
+			v = 0
+		}
+		if w.Float64(v) {
+			return c.fail(w)
+		}
+		return nil
+	case Null:
+		if err := d.Null(); err != nil {
+			return err
+		}
+		if w.Null() {
+			return c.fail(w)
+		}
+		return nil
+	case Bool:
+		v, err := d.Bool()
+		if err != nil {
+			return err
+		}
+		if w.Bool(v) {
+			return c.fail(w)
+		}
+		return nil
+	case Array:
+		return c.arr(d, w)
+	case Object:
+		return c.obj(d, w)
+	default:
+		return d.Skip()
+	}
+}
+
+func (c *canonicalizer) arr(d *Decoder, w *Writer) error {
+	if w.ArrStart() {
+		return c.fail(w)
+	}
+	first := true
+	if err := d.Arr(func(d *Decoder) error {
+		if !first && w.Comma() {
+			return c.fail(w)
+		}
+		first = false
+		return c.value(d, w)
+	}); err != nil {
+		return err
+	}
+	if w.ArrEnd() {
+		return c.fail(w)
+	}
+	return nil
+}
+
+func (c *canonicalizer) obj(d *Decoder, w *Writer) error {
+	var (
+		tmp   = &c.tmp
+		start = len(tmp.Buf)
+		first = len(c.members)
+	)
+	if err := d.consume('{'); err != nil {
+		return err
+	}
+	if err := d.incDepth(); err != nil {
+		return err
+	}
+	ch, err := d.more()
+	if err != nil {
+		return err
+	}
+	if ch != '}' {
+		d.unread()
+		frame := d.pushPath(true)
+		for n := 1; ; n++ {
+			if err := d.checkObjKeys(n); err != nil {
+				return err
+			}
+			if _, err := d.more(); err != nil {
+				return err
+			}
+			d.unread()
+			m := canonicalMember{
+				keyStart: len(tmp.Buf),
+				offset:   d.offset(),
+			}
+			k, err := d.key(value{buf: tmp.Buf})
+			if err != nil {
+				return errors.Wrap(err, "field name")
+			}
+			tmp.Buf = k.buf
+			m.keyEnd = len(tmp.Buf)
+			d.path[frame].key = tmp.Buf[m.keyStart:m.keyEnd]
+			if err := d.consume(':'); err != nil {
+				return errors.Wrap(err, `":" expected`)
+			}
+			if tmp.canonicalStr(tmp.Buf[m.keyStart:m.keyEnd]) || tmp.byte(':') {
+				return c.fail(tmp)
+			}
+			if err := c.value(d, tmp); err != nil {
+				return err
+			}
+			m.end = len(tmp.Buf)
+			c.members = append(c.members, m)
+
+			ch, err := d.more()
+			if err != nil {
+				return err
+			}
+			if ch == ',' {
+				if end, err := d.trailingComma('}'); err != nil {
+					return err
+				} else if !end {
+					continue
+				}
+			} else if ch != '}' {
+				return badToken(ch, d.offset()-1)
+			}
+			break
+		}
+		d.popPath(frame)
+	}
+	if err := d.decDepth(); err != nil {
+		return err
+	}
+
+	members := canonicalMembers{buf: tmp.Buf, members: c.members[first:]}
+	sort.Sort(members)
+	for i := 1; i < len(members.members); i++ {
+		if !members.Less(i-1, i) {
+			m := members.members[i]
+			return &DuplicateKeyError{
+				Key:    string(tmp.Buf[m.keyStart:m.keyEnd]),
+				Offset: m.offset,
+			}
+		}
+	}
+
+	out := len(tmp.Buf)
+	fail := w.ObjStart()
+	for i, m := range members.members {
+		if i > 0 {
+			fail = fail || w.Comma()
+		}
+		fail = fail || w.Raw(tmp.Buf[m.keyEnd:m.end])
+	}
+	fail = fail || w.ObjEnd()
+	if fail {
+		return c.fail(w)
+	}
+	if w == tmp {
+		// Move written object to start of members.
+		n := copy(tmp.Buf[start:], tmp.Buf[out:])
+		tmp.Buf = tmp.Buf[:start+n]
+	} else {
+		tmp.Buf = tmp.Buf[:start]
+	}
+	c.members = c.members[:first]
+	return nil
+}
+
+// canonicalMembers sorts object members by keys.
+type canonicalMembers struct {
+	buf     []byte
+	members []canonicalMember
+}
+
+func (s canonicalMembers) Len() int { return len(s.members) }
+
+func (s canonicalMembers) Swap(i, j int) {
+	s.members[i], s.members[j] = s.members[j], s.members[i]
+}
+
+func (s canonicalMembers) Less(i, j int) bool {
+	a, b := s.members[i], s.members[j]
+	return lessUTF16(s.buf[a.keyStart:a.keyEnd], s.buf[b.keyStart:b.keyEnd])
+}
+
+// lessUTF16 reports whether UTF-8 string a is less than b when both are
+// compared as UTF-16 code units.
+func lessUTF16(a, b []byte) bool {
+	for len(a) > 0 && len(b) > 0 {
+		if a[0] < utf8.RuneSelf && b[0] < utf8.RuneSelf {
+			// Fast path for ASCII.
+			if a[0] != b[0] {
+				return a[0] < b[0]
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		ra, na := utf8.DecodeRune(a)
+		rb, nb := utf8.DecodeRune(b)
+		if ra != rb {
+			return utf16Units(ra) < utf16Units(rb)
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return len(a) < len(b)
+}
+
+// utf16Units returns UTF-16 encoding of rune as single comparable value.
+func utf16Units(r rune) uint32 {
+	if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+		return uint32(r1)<<16 | uint32(r2)
+	}
+	return uint32(r) << 16
+}
+
+// canonicalStr writes string with minimal escaping, as defined by
+// RFC 8785, Section 3.2.2.2.
+func (w *Writer) canonicalStr(v []byte) (fail bool) {
+	fail = w.byte('"')
+	start := 0
+	for i := 0; i < len(v) && !fail; i++ {
+		b := v[i]
+		if safeSet[b] == 0 {
+			continue
+		}
+		fail = fail || writeStreamByteseq(w, v[start:i])
+		switch b {
+		case '\\', '"':
+			fail = fail || w.twoBytes('\\', b)
+		case '\b':
+			fail = fail || w.twoBytes('\\', 'b')
+		case '\f':
+			fail = fail || w.twoBytes('\\', 'f')
+		case '\n':
+			fail = fail || w.twoBytes('\\', 'n')
+		case '\r':
+			fail = fail || w.twoBytes('\\', 'r')
+		case '\t':
+			fail = fail || w.twoBytes('\\', 't')
+		default:
+			fail = fail || w.rawStr(`\u00`) || w.twoBytes(hexChars[b>>4], hexChars[b&0xF])
+		}
+		start = i + 1
+	}
+	return fail || writeStreamByteseq(w, v[start:]) || w.byte('"')
+}
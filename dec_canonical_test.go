@@ -0,0 +1,153 @@
+package jx
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_Canonical(t *testing.T) {
+	for _, tt := range []struct {
+		Name   string
+		Input  string
+		Expect string
+	}{
+		{
+			// RFC 8785, Section 3.2.2.
+			Name: "RFC",
+			Input: `{
+  "numbers": [333333333.33333329, 1E30, 4.50,
+              2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`,
+			Expect: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			// RFC 8785, Section 3.2.3.
+			Name: "Sorting",
+			Input: `{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`,
+			Expect: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\"," +
+				"\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\"," +
+				"\"\U0001F600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{
+			Name:   "Nested",
+			Input:  `{"b": {"d": [{"f": 1, "e": 2}], "c": {}}, "a": [], "ab": -0}`,
+			Expect: `{"a":[],"ab":0,"b":{"c":{},"d":[{"e":2,"f":1}]}}`,
+		},
+		{
+			Name:   "Escapes",
+			Input:  `"\b\f\u0001\u007f <>&"`,
+			Expect: "\"\\b\\f\\u0001\u007f <>&\"",
+		},
+		{
+			Name:   "Prefix",
+			Input:  `{"a":1,"aa":2,"":3,"A":4}`,
+			Expect: `{"":3,"A":4,"a":1,"aa":2}`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.Name, testBufferReader(tt.Input, func(t *testing.T, d *Decoder) {
+			var w Writer
+			require.NoError(t, d.Canonical(&w))
+			require.Equal(t, tt.Expect, w.String())
+		}))
+	}
+}
+
+func TestCanonical_Numbers(t *testing.T) {
+	// RFC 8785, Appendix B.
+	for _, tt := range []struct {
+		Bits   uint64
+		Expect string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	} {
+		input := strconv.FormatFloat(math.Float64frombits(tt.Bits), 'g', -1, 64)
+		var w Writer
+		require.NoError(t, DecodeStr(input).Canonical(&w), input)
+		require.Equal(t, tt.Expect, w.String(), input)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	runTestdata(t.Fatal, func(name string, data []byte) {
+		t.Run(name, func(t *testing.T) {
+			var first bytes.Buffer
+			require.NoError(t, Canonical(&first, bytes.NewReader(data)))
+			require.True(t, Valid(first.Bytes()))
+
+			// Canonical form is idempotent.
+			var second bytes.Buffer
+			require.NoError(t, Canonical(&second, bytes.NewReader(first.Bytes())))
+			require.Equal(t, first.String(), second.String())
+		})
+	})
+}
+
+func TestCanonical_Errors(t *testing.T) {
+	for _, input := range []string{
+		``,
+		`{`,
+		`{"a":1,"a":2}`,
+		`{"a":1,"b":{"c":1,"c":1}}`,
+		`1e400`,
+		`"\ud800"`,
+		"\"\xff\"",
+		`[1,]`,
+		`[1] 2`,
+	} {
+		input := input
+		t.Run(input, func(t *testing.T) {
+			require.Error(t, Canonical(io.Discard, strings.NewReader(input)))
+		})
+	}
+	t.Run("DuplicateKey", func(t *testing.T) {
+		err := Canonical(io.Discard, strings.NewReader(`{"b":1,"a":{},"b":2}`))
+		var dupErr *DuplicateKeyError
+		require.ErrorAs(t, err, &dupErr)
+		require.Equal(t, "b", dupErr.Key)
+		require.Equal(t, 14, dupErr.Offset)
+	})
+	t.Run("Write", func(t *testing.T) {
+		err := Canonical(&errWriter{err: io.ErrClosedPipe}, strings.NewReader(`{"a":[1]}`))
+		require.ErrorIs(t, err, io.ErrClosedPipe)
+	})
+}
@@ -1,31 +1,55 @@
 package jx
 
-import (
-	"bytes"
-	"io"
-)
-
 // Capture calls f and then rolls back to state before call.
 func (d *Decoder) Capture(f func(d *Decoder) error) error {
 	if f == nil {
 		return nil
 	}
 
-	if d.reader != nil {
-		// TODO(tdakkota): May it be more efficient?
+	switch {
+	case d.at != nil:
+		// Input is re-read from io.ReaderAt if buffer is overwritten.
 		var (
-			buf          bytes.Buffer
-			streamOffset = d.streamOffset
+			streamOffset    = d.streamOffset
+			streamLines     = d.streamLines
+			streamLineStart = d.streamLineStart
+			streamPrevStart = d.streamPrevLineStart
+			truncated       = d.truncated
+			tail            = d.tail
 		)
-		reader := io.TeeReader(d.reader, &buf)
 		defer func() {
-			d.reader = io.MultiReader(&buf, d.reader)
-			d.streamOffset = streamOffset
+			d.streamLines, d.streamLineStart, d.streamPrevLineStart = streamLines, streamLineStart, streamPrevStart
+			d.truncated = truncated
 		}()
-		d.reader = reader
+		return d.capture(f, func() error {
+			return d.refillAt(streamOffset, tail)
+		})
+	case d.reader != nil:
+		// Input is kept in buffer by checkpoint.
+		c := d.Checkpoint()
+		err := f(d)
+		if rerr := d.Rewind(c); rerr != nil {
+			return rerr
+		}
+		d.Commit(c)
+		return err
 	}
-	head, tail, depth := d.head, d.tail, d.depth
+	return d.capture(f, nil)
+}
+
+// capture calls f and restores buffer state, calling refill before that
+// if not nil.
+func (d *Decoder) capture(f func(d *Decoder) error, refill func() error) error {
+	head, tail, depth, path, keys, pins := d.head, d.tail, d.depth, len(d.path), len(d.keys), len(d.pins)
 	err := f(d)
+	d.pins = d.pins[:pins]
+	if refill != nil {
+		if rerr := refill(); rerr != nil {
+			return rerr
+		}
+	}
 	d.head, d.tail, d.depth = head, tail, depth
+	d.popPath(path)
+	d.keys = d.keys[:keys]
 	return err
 }
@@ -0,0 +1,135 @@
+package jx
+
+import "github.com/go-faster/errors"
+
+// Checkpoint is saved position of Decoder, see Decoder.Checkpoint.
+type Checkpoint struct {
+	pin    int // index of pin plus one, zero is not a checkpoint
+	gen    uint64
+	offset int
+	depth  int
+
+	path      int
+	pathFrame pathFrame // top path frame
+	tokens    int
+	token     tokenFrame // top token frame
+
+	keys  int
+	marks int // index of key set states in keyMarks
+}
+
+// pin is position of active checkpoint.
+type pin struct {
+	offset int
+	gen    uint64 // zero if pin is not checkpoint
+}
+
+// keyMark is state of key set saved by checkpoint.
+//
+// Key sets are append-only while they are referenced by checkpoint, so
+// only slices are saved, not keys.
+type keyMark struct {
+	gen  uint64
+	buf  []byte
+	ends []int
+}
+
+var errCheckpoint = errors.New("checkpoint is not active")
+
+// Checkpoint saves current position, so decoder can be rewound to it, for
+// example, after reading discriminator field of object.
+//
+// Input after checkpoint is kept in buffer until Commit, so reader-backed
+// decoder decodes it again without reading. Checkpoints can be nested and
+// should be committed in reverse order.
+//
+// State of ArrIter and ObjIter is not saved, copy iterator to restore it.
+func (d *Decoder) Checkpoint() Checkpoint {
+	d.checkpoints++
+	d.pins = append(d.pins, pin{offset: d.offset(), gen: d.checkpoints})
+	c := Checkpoint{
+		pin:    len(d.pins),
+		gen:    d.checkpoints,
+		offset: d.offset(),
+		depth:  d.depth,
+		path:   len(d.path),
+		tokens: len(d.tokens),
+		keys:   len(d.keys),
+		marks:  len(d.keyMarks),
+	}
+	if n := len(d.path); n > 0 {
+		c.pathFrame = d.path[n-1]
+	}
+	if n := len(d.tokens); n > 0 {
+		c.token = d.tokens[n-1]
+	}
+	for _, s := range d.keys {
+		d.keyMarks = append(d.keyMarks, keyMark{gen: s.gen, buf: s.buf, ends: s.ends})
+	}
+	if len(d.keys) > d.keysPinned {
+		d.keysPinned = len(d.keys)
+	}
+	return c
+}
+
+// active reports whether checkpoint is not committed.
+func (d *Decoder) active(c Checkpoint) bool {
+	return c.pin > 0 && c.pin <= len(d.pins) && d.pins[c.pin-1].gen == c.gen
+}
+
+// unpin releases all checkpoints.
+func (d *Decoder) unpin() {
+	d.pins = d.pins[:0]
+	d.keyMarks = d.keyMarks[:0]
+	d.keysPinned = 0
+}
+
+// Rewind returns decoder to checkpoint c, so input after it is decoded
+// again. Checkpoints created after c are released, c remains active.
+func (d *Decoder) Rewind(c Checkpoint) error {
+	if !d.active(c) {
+		return errCheckpoint
+	}
+	d.pins = d.pins[:c.pin]
+	d.head = c.offset - d.streamOffset
+	d.depth = c.depth
+
+	d.path = d.path[:c.path]
+	if n := len(d.path); n > 0 {
+		d.path[n-1] = c.pathFrame
+	}
+	d.tokens = d.tokens[:c.tokens]
+	if n := len(d.tokens); n > 0 {
+		d.tokens[n-1] = c.token
+	}
+	d.keyMarks = d.keyMarks[:c.marks+c.keys]
+	d.keys = d.keys[:c.keys]
+	for i, m := range d.keyMarks[c.marks:] {
+		s := &d.keys[i]
+		if s.gen == m.gen {
+			s.truncate(len(m.ends))
+			continue
+		}
+		// Set was removed and its place is reused.
+		*s = keySet{gen: m.gen, buf: m.buf, ends: m.ends}
+		if len(s.ends) > keySetLinear {
+			s.rehash()
+		}
+	}
+	return nil
+}
+
+// Commit releases checkpoint c and checkpoints created after it.
+//
+// Decoder position is not changed.
+func (d *Decoder) Commit(c Checkpoint) {
+	if !d.active(c) {
+		return
+	}
+	if c.pin == 1 {
+		d.unpin()
+		return
+	}
+	d.pins = d.pins[:c.pin-1]
+	d.keyMarks = d.keyMarks[:c.marks]
+}
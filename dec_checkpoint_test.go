@@ -0,0 +1,316 @@
+package jx
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_Checkpoint(t *testing.T) {
+	const input = `[{"data":{"values":[1,2,3,4,5,6,7,8,9,10],"name":"long name of value"},"type":"b"},{"type":"c"}]`
+	testDecoders(t, input, func(t *testing.T, d *Decoder) {
+		var types []string
+		require.NoError(t, d.Arr(func(d *Decoder) error {
+			// Find discriminator first.
+			c := d.Checkpoint()
+			var typ string
+			if err := d.Obj(func(d *Decoder, key string) error {
+				if key != "type" {
+					return d.Skip()
+				}
+				v, err := d.Str()
+				typ = v
+				return err
+			}); err != nil {
+				return err
+			}
+			if err := d.Rewind(c); err != nil {
+				return err
+			}
+			defer d.Commit(c)
+
+			// Decode again.
+			var raw []string
+			if err := d.Obj(func(d *Decoder, key string) error {
+				v, err := d.Raw()
+				raw = append(raw, key+"="+v.String())
+				return err
+			}); err != nil {
+				return err
+			}
+			types = append(types, typ+":"+strings.Join(raw, ","))
+			return nil
+		}))
+		require.Equal(t, []string{
+			`b:data={"values":[1,2,3,4,5,6,7,8,9,10],"name":"long name of value"},type="b"`,
+			`c:type="c"`,
+		}, types)
+		require.Empty(t, d.pins)
+	})
+}
+
+func TestDecoder_CheckpointNested(t *testing.T) {
+	const input = `[1, 2, 3, 4, 5]`
+	testDecoders(t, input, func(t *testing.T, d *Decoder) {
+		iter, err := d.ArrIter()
+		require.NoError(t, err)
+		next := func() int {
+			require.True(t, iter.Next())
+			v, err := d.Int()
+			require.NoError(t, err)
+			return v
+		}
+
+		require.Equal(t, 1, next())
+		outer := d.Checkpoint()
+		require.Equal(t, 2, next())
+		inner := d.Checkpoint()
+		require.Equal(t, 3, next())
+		require.Equal(t, 4, next())
+
+		require.NoError(t, d.Rewind(inner))
+		require.Equal(t, 3, next())
+
+		// Rewind to outer releases inner.
+		require.NoError(t, d.Rewind(outer))
+		require.ErrorIs(t, d.Rewind(inner), errCheckpoint)
+		require.Equal(t, 2, next())
+		require.Equal(t, 3, next())
+
+		d.Commit(outer)
+		require.ErrorIs(t, d.Rewind(outer), errCheckpoint)
+		d.Commit(outer)
+		require.ErrorIs(t, d.Rewind(Checkpoint{}), errCheckpoint)
+
+		require.Equal(t, 4, next())
+		require.Equal(t, 5, next())
+		require.False(t, iter.Next())
+		require.NoError(t, iter.Err())
+	})
+}
+
+func TestDecoder_CheckpointDuplicateKeys(t *testing.T) {
+	const input = `{"a":1,"b":2,"c":3}`
+	testDecoders(t, input, func(t *testing.T, d *Decoder) {
+		d.SetDisallowDuplicateKeys(true)
+		iter, err := d.ObjIter()
+		require.NoError(t, err)
+		readRest := func() (keys []string) {
+			for iter.Next() {
+				keys = append(keys, string(iter.Key()))
+				require.NoError(t, d.Skip())
+			}
+			require.NoError(t, iter.Err())
+			return keys
+		}
+
+		require.True(t, iter.Next())
+		require.NoError(t, d.Skip())
+		// Iterator state is not part of checkpoint.
+		saved := iter
+		c := d.Checkpoint()
+		require.Equal(t, []string{"b", "c"}, readRest())
+
+		// Keys read after checkpoint are not duplicates.
+		require.NoError(t, d.Rewind(c))
+		iter = saved
+		d.Commit(c)
+		require.Equal(t, []string{"b", "c"}, readRest())
+	})
+}
+
+func TestDecoder_CheckpointManyKeys(t *testing.T) {
+	var e Encoder
+	e.ObjStart()
+	for i := 0; i < 100; i++ {
+		e.FieldStart(strconv.Itoa(i))
+		e.Int(i)
+	}
+	e.FieldStart("0")
+	e.Int(0)
+	e.ObjEnd()
+	input := e.String()
+
+	testDecoders(t, input, func(t *testing.T, d *Decoder) {
+		d.SetDisallowDuplicateKeys(true)
+		iter, err := d.ObjIter()
+		require.NoError(t, err)
+		readRest := func(n int) {
+			for i := 0; i < n; i++ {
+				require.True(t, iter.Next())
+				require.NoError(t, d.Skip())
+			}
+		}
+		readRest(50)
+		saved := iter
+		c := d.Checkpoint()
+		readRest(50)
+
+		require.NoError(t, d.Rewind(c))
+		iter = saved
+		d.Commit(c)
+		readRest(50)
+		// Duplicate of key read before checkpoint.
+		require.False(t, iter.Next())
+		var dup *DuplicateKeyError
+		require.ErrorAs(t, iter.Err(), &dup)
+		require.Equal(t, "0", dup.Key)
+	})
+}
+
+func TestDecoder_CheckpointOuterKeys(t *testing.T) {
+	const input = `{"a":{"x":1,"y":2},"b":{"x":1,"y":2,"z":3},"c":1}`
+	testDecoders(t, input, func(t *testing.T, d *Decoder) {
+		d.SetDisallowDuplicateKeys(true)
+		outer, err := d.ObjIter()
+		require.NoError(t, err)
+		require.True(t, outer.Next())
+		inner, err := d.ObjIter()
+		require.NoError(t, err)
+		require.True(t, inner.Next())
+		require.NoError(t, d.Skip())
+
+		readRest := func() (keys []string) {
+			for inner.Next() {
+				keys = append(keys, string(inner.Key()))
+				require.NoError(t, d.Skip())
+			}
+			require.NoError(t, inner.Err())
+			for outer.Next() {
+				keys = append(keys, string(outer.Key()))
+				// Key set of "a" is reused by "b".
+				require.NoError(t, d.Skip())
+			}
+			require.NoError(t, outer.Err())
+			return keys
+		}
+		savedOuter, savedInner := outer, inner
+		c := d.Checkpoint()
+		require.Equal(t, []string{"y", "b", "c"}, readRest())
+
+		// Keys of outer object read after checkpoint are not duplicates.
+		require.NoError(t, d.Rewind(c))
+		outer, inner = savedOuter, savedInner
+		d.Commit(c)
+		require.Equal(t, []string{"y", "b", "c"}, readRest())
+	})
+}
+
+func TestDecoder_CheckpointStale(t *testing.T) {
+	d := DecodeStr(`[1,2]`)
+	c := d.Checkpoint()
+	d.Commit(c)
+	// New checkpoint at same position does not revive committed one.
+	c2 := d.Checkpoint()
+	require.ErrorIs(t, d.Rewind(c), errCheckpoint)
+	require.NoError(t, d.Rewind(c2))
+}
+
+func TestDecoder_CheckpointError(t *testing.T) {
+	const input = "[\n1,\n2,\n  x]"
+	readRest := func(d *Decoder, iter *ArrIter) error {
+		for iter.Next() {
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		}
+		return iter.Err()
+	}
+	read := func(d *Decoder, checkpoint bool) error {
+		iter, err := d.ArrIter()
+		require.NoError(t, err)
+		require.True(t, iter.Next())
+		require.NoError(t, d.Skip())
+		if checkpoint {
+			saved := iter
+			c := d.Checkpoint()
+			require.Error(t, readRest(d, &iter))
+			require.NoError(t, d.Rewind(c))
+			iter = saved
+			d.Commit(c)
+		}
+		return readRest(d, &iter)
+	}
+	expected := read(DecodeStr(input), false)
+	require.Error(t, expected)
+
+	testDecoders(t, input, func(t *testing.T, d *Decoder) {
+		require.EqualError(t, read(d, true), expected.Error())
+	})
+}
+
+// testDecoders runs f with Decoder of input for every kind of input.
+func testDecoders(t *testing.T, input string, f func(t *testing.T, d *Decoder)) {
+	t.Helper()
+	for _, tt := range []struct {
+		Name string
+		New  func() *Decoder
+	}{
+		{"Bytes", func() *Decoder { return DecodeStr(input) }},
+		{"Reader", func() *Decoder { return Decode(strings.NewReader(input), 4) }},
+		{"OneByteReader", func() *Decoder { return Decode(iotest.OneByteReader(strings.NewReader(input)), 1) }},
+		{"ReaderAt", func() *Decoder { return DecodeReaderAt(strings.NewReader(input), int64(len(input)), 4) }},
+	} {
+		tt := tt
+		t.Run(tt.Name, func(t *testing.T) {
+			f(t, tt.New())
+		})
+	}
+}
+
+func BenchmarkDecoder_Checkpoint(b *testing.B) {
+	data := benchData
+	r := bytes.NewReader(data)
+	d := Decode(r, 512)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r.Reset(data)
+		d.Reset(r)
+		if err := d.Arr(func(d *Decoder) error {
+			c := d.Checkpoint()
+			if err := d.Skip(); err != nil {
+				return err
+			}
+			if err := d.Rewind(c); err != nil {
+				return err
+			}
+			d.Commit(c)
+			return d.Skip()
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecoder_CheckpointOneByte(b *testing.B) {
+	data := benchData
+	r := bytes.NewReader(data)
+	d := Decode(r, 512)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r.Reset(data)
+		d.Reset(iotest.OneByteReader(r))
+		// Whole input is kept in buffer.
+		c := d.Checkpoint()
+		if err := d.Skip(); err != nil {
+			b.Fatal(err)
+		}
+		if err := d.Rewind(c); err != nil {
+			b.Fatal(err)
+		}
+		d.Commit(c)
+		if err := d.Skip(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package jx
+
+import "fmt"
+
+// DuplicateKeyError is returned if object has duplicate key and duplicate
+// keys are disallowed.
+type DuplicateKeyError struct {
+	Key    string
+	Offset int // offset of duplicate key
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate key %q at %d", e.Key, e.Offset)
+}
+
+// SetDisallowDuplicateKeys makes Obj, ObjBytes, ObjIter, Skip and Validate
+// return *DuplicateKeyError if object has duplicate keys.
+//
+// Option is kept on Reset and ResetBytes, but reset on PutDecoder.
+func (d *Decoder) SetDisallowDuplicateKeys(v bool) {
+	d.disallowDupKeys = v
+}
+
+// keySetLinear is maximum count of keys in keySet that are checked by
+// linear scan, larger sets use hash table.
+const keySetLinear = 16
+
+// keySet is set of keys of single object.
+//
+// Keys are stored in single buffer and indexed by open addressing hash table
+// for large objects, so reused set does not allocate.
+type keySet struct {
+	gen  uint64 // generation, see Decoder.keyGen
+	buf  []byte // concatenated keys
+	ends []int  // end offsets of keys in buf
+	// table is hash table of key indexes plus one, zero is empty slot.
+	//
+	// Used only if there are more than keySetLinear keys.
+	table []int32
+}
+
+func (s *keySet) reset() {
+	s.buf = s.buf[:0]
+	s.ends = s.ends[:0]
+	s.table = s.table[:0]
+}
+
+// truncate removes keys added after first n ones.
+func (s *keySet) truncate(n int) {
+	if n <= keySetLinear {
+		s.table = s.table[:0]
+	} else {
+		// Keys are removed in reverse order of addition, so probe sequences
+		// of remaining keys are not broken.
+		for i := len(s.ends) - 1; i >= n; i-- {
+			s.table[s.slot(s.key(i))] = 0
+		}
+	}
+	end := 0
+	if n > 0 {
+		end = s.ends[n-1]
+	}
+	s.buf = s.buf[:end]
+	s.ends = s.ends[:n]
+}
+
+func (s *keySet) key(i int) []byte {
+	start := 0
+	if i > 0 {
+		start = s.ends[i-1]
+	}
+	return s.buf[start:s.ends[i]]
+}
+
+// keyHash is FNV-1a hash of key.
+func keyHash(key []byte) uint32 {
+	h := uint32(2166136261)
+	for _, c := range key {
+		h ^= uint32(c)
+		h *= 16777619
+	}
+	return h
+}
+
+// slot returns index of table slot that contains key or empty slot for it.
+func (s *keySet) slot(key []byte) int {
+	mask := len(s.table) - 1
+	for i := int(keyHash(key)) & mask; ; i = (i + 1) & mask {
+		idx := s.table[i]
+		if idx == 0 || string(s.key(int(idx-1))) == string(key) {
+			return i
+		}
+	}
+}
+
+// rehash resizes table to fit twice as many keys as there are.
+func (s *keySet) rehash() {
+	size := 64
+	for size < len(s.ends)*2 {
+		size *= 2
+	}
+	if cap(s.table) >= size {
+		s.table = s.table[:size]
+		for i := range s.table {
+			s.table[i] = 0
+		}
+	} else {
+		s.table = make([]int32, size)
+	}
+	for i := range s.ends {
+		s.table[s.slot(s.key(i))] = int32(i + 1)
+	}
+}
+
+// add adds key to set, returning false if key is already present.
+func (s *keySet) add(key []byte) bool {
+	if len(s.table) > 0 {
+		slot := s.slot(key)
+		if s.table[slot] != 0 {
+			return false
+		}
+		s.buf = append(s.buf, key...)
+		s.ends = append(s.ends, len(s.buf))
+		s.table[slot] = int32(len(s.ends))
+		if len(s.ends)*2 > len(s.table) {
+			s.rehash()
+		}
+		return true
+	}
+
+	for i := range s.ends {
+		if string(s.key(i)) == string(key) {
+			return false
+		}
+	}
+	s.buf = append(s.buf, key...)
+	s.ends = append(s.ends, len(s.buf))
+	if len(s.ends) > keySetLinear {
+		s.rehash()
+	}
+	return true
+}
+
+// pushKeys pushes key set for new object, returning its index or -1 if
+// duplicate keys are allowed.
+func (d *Decoder) pushKeys() int {
+	if !d.disallowDupKeys {
+		return -1
+	}
+	idx := len(d.keys)
+	switch {
+	case idx < d.keysPinned:
+		// Storage of set can be referenced by checkpoint.
+		d.keys = d.keys[:idx+1]
+		d.keys[idx] = keySet{}
+	case idx < cap(d.keys):
+		// Reuse previously allocated set.
+		d.keys = d.keys[:idx+1]
+		d.keys[idx].reset()
+	default:
+		d.keys = append(d.keys, keySet{})
+	}
+	d.keyGen++
+	d.keys[idx].gen = d.keyGen
+	return idx
+}
+
+// popKeys removes key set with given index and all sets above it.
+func (d *Decoder) popKeys(idx int) {
+	if idx < 0 {
+		return
+	}
+	d.keys = d.keys[:idx]
+}
@@ -0,0 +1,154 @@
+package jx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-faster/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_SetDisallowDuplicateKeys(t *testing.T) {
+	large := func(dup bool) string {
+		var b strings.Builder
+		b.WriteString("{")
+		for i := 0; i < 100; i++ {
+			if i != 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(&b, `"key%d":%d`, i, i)
+		}
+		if dup {
+			b.WriteString(`,"key42":1`)
+		}
+		b.WriteString("}")
+		return b.String()
+	}
+	ops := []struct {
+		Name string
+		// Wrapped reports whether error is wrapped to *DecodeError.
+		Wrapped bool
+		Do      func(d *Decoder) error
+	}{
+		{"Validate", true, func(d *Decoder) error { return d.Validate() }},
+		{"Skip", false, func(d *Decoder) error { return d.Skip() }},
+		{"Any", true, decoderOnlyError((*Decoder).Any)},
+		{"ObjIter", false, func(d *Decoder) error {
+			if d.Next() != Object {
+				return d.Skip()
+			}
+			iter, err := d.ObjIter()
+			if err != nil {
+				return err
+			}
+			for iter.Next() {
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+			return iter.Err()
+		}},
+	}
+	for i, tt := range []struct {
+		Input  string
+		Key    string
+		Offset int
+	}{
+		{Input: `{}`},
+		{Input: `{"a":1,"b":2}`},
+		{Input: `{"a":{"a":1},"b":[{"a":1},{"a":2}]}`},
+		{Input: `{"a":1, "a":2}`, Key: "a", Offset: 8},
+		{Input: `{"a":1,"b":{"c":1,"c":2}}`, Key: "c", Offset: 18},
+		{Input: `[{"a":1},{"b":1,  "b":2}]`, Key: "b", Offset: 18},
+		{Input: `{"a":1,"a":2}`, Key: "a", Offset: 7},
+		{Input: large(false)},
+		{Input: large(true), Key: "key42", Offset: len(large(false))},
+	} {
+		tt := tt
+		for _, o := range ops {
+			o := o
+			t.Run(fmt.Sprintf("Test%d/%s", i+1, o.Name), testBufferReader(tt.Input, func(t *testing.T, d *Decoder) {
+				d.SetDisallowDuplicateKeys(true)
+				err := o.Do(d)
+				if tt.Key == "" {
+					require.NoError(t, err)
+					return
+				}
+				var dupErr *DuplicateKeyError
+				require.ErrorAs(t, err, &dupErr)
+				require.Equal(t, tt.Key, dupErr.Key)
+				require.Equal(t, tt.Offset, dupErr.Offset)
+				if !o.Wrapped {
+					return
+				}
+
+				var decErr *DecodeError
+				require.ErrorAs(t, err, &decErr)
+				require.Equal(t, tt.Offset, decErr.Offset)
+			}))
+		}
+		t.Run(fmt.Sprintf("Test%d/Allowed", i+1), testBufferReader(tt.Input, func(t *testing.T, d *Decoder) {
+			require.NoError(t, d.Validate())
+		}))
+	}
+}
+
+func TestDecoder_DuplicateKeysObj(t *testing.T) {
+	d := DecodeStr(`{"a":1,"b":2,"a":3}`)
+	d.SetDisallowDuplicateKeys(true)
+
+	var keys []string
+	err := d.Obj(func(d *Decoder, key string) error {
+		keys = append(keys, key)
+		return d.Skip()
+	})
+	var dupErr *DuplicateKeyError
+	require.True(t, errors.As(err, &dupErr))
+	require.Equal(t, "a", dupErr.Key)
+	require.Equal(t, []string{"a", "b"}, keys)
+
+	t.Run("Reuse", func(t *testing.T) {
+		d.ResetBytes([]byte(`{"a":1,"b":2}`))
+		require.NoError(t, d.Validate())
+	})
+	t.Run("Reset", func(t *testing.T) {
+		d := GetDecoder()
+		d.SetDisallowDuplicateKeys(true)
+		PutDecoder(d)
+		d.ResetBytes([]byte(`{"a":1,"a":2}`))
+		require.NoError(t, d.Validate())
+	})
+}
+
+func TestKeySet(t *testing.T) {
+	var s keySet
+	for round := 0; round < 2; round++ {
+		s.reset()
+		for i := 0; i < 100; i++ {
+			key := []byte(fmt.Sprintf("key%d", i))
+			require.True(t, s.add(key))
+			require.False(t, s.add(key))
+		}
+		require.True(t, s.add([]byte("")))
+		require.False(t, s.add([]byte("")))
+	}
+}
+
+func BenchmarkDecoder_DuplicateKeys(b *testing.B) {
+	for _, disallow := range []bool{false, true} {
+		b.Run(fmt.Sprintf("Disallow=%v", disallow), func(b *testing.B) {
+			d := GetDecoder()
+			d.SetDisallowDuplicateKeys(disallow)
+			b.ReportAllocs()
+			b.SetBytes(int64(len(benchData)))
+
+			for i := 0; i < b.N; i++ {
+				d.ResetBytes(benchData)
+				if err := d.Validate(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
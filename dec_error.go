@@ -1,6 +1,12 @@
 package jx
 
-import "fmt"
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/go-faster/errors"
+)
 
 // badTokenErr means that Token was unexpected while decoding.
 type badTokenErr struct {
@@ -15,3 +21,125 @@ func (e *badTokenErr) Error() string {
 func badToken(c byte, offset int) error {
 	return &badTokenErr{Token: c, Offset: offset}
 }
+
+// DecodeError describes decoding failure and its location in input.
+//
+// Use errors.As to retrieve it from errors returned by Decoder.
+type DecodeError struct {
+	// Offset is absolute byte offset in input.
+	Offset int
+	// Line and Column are 1-based, Column is counted in bytes.
+	Line   int
+	Column int
+	// Path contains object keys and array indexes, formatted as decimal,
+	// from the root value to the value where failure happened.
+	Path []string
+	// Err is underlying error.
+	Err error
+}
+
+// Pointer returns Path as RFC 6901 JSON Pointer.
+func (e *DecodeError) Pointer() string {
+	var ptr []byte
+	for _, tok := range e.Path {
+		ptr = appendPointerToken(ptr, tok)
+	}
+	return string(ptr)
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("at %q (line %d, column %d, offset %d): %s",
+		e.Pointer(), e.Line, e.Column, e.Offset, e.Err,
+	)
+}
+
+// Unwrap returns underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// pathFrame is element of Decoder path stack.
+type pathFrame struct {
+	key   []byte
+	index int
+	obj   bool
+}
+
+// pushPath adds new path frame, returning its index.
+func (d *Decoder) pushPath(obj bool) int {
+	idx := len(d.path)
+	d.path = append(d.path, pathFrame{obj: obj})
+	return idx
+}
+
+// popPath removes path frame with given index and all frames above it.
+func (d *Decoder) popPath(idx int) {
+	d.path = d.path[:idx]
+}
+
+// decodeErr wraps err to *DecodeError using current position and path,
+// if it is not already wrapped.
+func (d *Decoder) decodeErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := errors.Into[*DecodeError](err); ok {
+		return err
+	}
+	offset := d.offset()
+	if e, ok := errors.Into[*badTokenErr](err); ok {
+		offset = e.Offset
+	} else if e, ok := errors.Into[*DuplicateKeyError](err); ok {
+		offset = e.Offset
+	}
+	line, column := d.position(offset)
+	return &DecodeError{
+		Offset: offset,
+		Line:   line,
+		Column: column,
+		Path:   d.pathTokens(),
+		Err:    err,
+	}
+}
+
+// pathTokens returns current path as keys and decimal indexes.
+func (d *Decoder) pathTokens() []string {
+	path := make([]string, len(d.path))
+	for i, f := range d.path {
+		if f.obj {
+			path[i] = string(f.key)
+		} else {
+			path[i] = strconv.Itoa(f.index)
+		}
+	}
+	return path
+}
+
+// position computes line and column of given absolute offset.
+//
+// Offset can precede streamOffset if token spans refill of buffer. Only two
+// last line starts before streamOffset are known, so for earlier offsets
+// line is estimated and column is 1.
+func (d *Decoder) position(offset int) (line, column int) {
+	line, lineStart := d.streamLines, d.streamLineStart
+	if offset < lineStart {
+		if offset >= d.streamPrevLineStart {
+			return line, offset - d.streamPrevLineStart + 1
+		}
+		if line > 1 {
+			line--
+		}
+		return line, 1
+	}
+	if rel := offset - d.streamOffset; rel > 0 {
+		if rel > d.tail {
+			rel = d.tail
+		}
+		buf := d.buf[:rel]
+		if n := bytes.Count(buf, []byte{'\n'}); n > 0 {
+			line += n
+			lineStart = d.streamOffset + bytes.LastIndexByte(buf, '\n') + 1
+		}
+	}
+	return line + 1, offset - lineStart + 1
+}
@@ -1,9 +1,13 @@
 package jx
 
 import (
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/go-faster/errors"
 )
 
 func Test_badTokenErr_Error(t *testing.T) {
@@ -14,3 +18,151 @@ func Test_badTokenErr_Error(t *testing.T) {
 	s := error(e).Error()
 	require.Equal(t, "unexpected byte 99 'c' at 10", s)
 }
+
+func TestDecodeError(t *testing.T) {
+	const input = "{\n  \"a\": [1, {\"b\": tru}],\n  \"c\": 2\n}"
+	crawl := func(t *testing.T, d *Decoder) {
+		err := crawlValue(d)
+		require.Error(t, err)
+
+		e, ok := errors.Into[*DecodeError](err)
+		require.True(t, ok, "%+v", err)
+		require.Equal(t, []string{"a", "1", "b"}, e.Path)
+		require.Equal(t, "/a/1/b", e.Pointer())
+		require.Equal(t, 2, e.Line)
+		require.Equal(t, 22, e.Offset)
+		require.Equal(t, 21, e.Column)
+		require.Equal(t, byte('}'), input[e.Offset])
+		require.Contains(t, e.Error(), `at "/a/1/b" (line 2, column 21, offset 22)`)
+	}
+	t.Run("Crawl", testBufferReader(input, crawl))
+	t.Run("Iter", testBufferReader(input, func(t *testing.T, d *Decoder) {
+		iter, err := d.ObjIter()
+		require.NoError(t, err)
+		require.True(t, iter.Next())
+		require.Equal(t, "a", string(iter.Key()))
+		arr, err := d.ArrIter()
+		require.NoError(t, err)
+		require.True(t, arr.Next())
+		require.NoError(t, d.Skip())
+		require.True(t, arr.Next())
+		crawl(t, d)
+	}))
+	t.Run("Callback", testBufferReader(input, func(t *testing.T, d *Decoder) {
+		errTest := errors.New("test")
+		err := d.Obj(func(d *Decoder, key string) error {
+			return d.Arr(func(d *Decoder) error {
+				if d.Next() == Object {
+					return errTest
+				}
+				return d.Skip()
+			})
+		})
+		require.ErrorIs(t, err, errTest)
+
+		e, ok := errors.Into[*DecodeError](err)
+		require.True(t, ok)
+		require.Equal(t, "/a/1", e.Pointer())
+		require.Equal(t, 2, e.Line)
+		require.Equal(t, 12, e.Column)
+	}))
+	t.Run("IterSyntax", testBufferReader(`[[1, 2], [3 4]]`, func(t *testing.T, d *Decoder) {
+		err := crawlValue(d)
+		e, ok := errors.Into[*DecodeError](err)
+		require.True(t, ok)
+		require.Equal(t, "/1/0", e.Pointer())
+		require.Equal(t, 12, e.Offset)
+
+		d.ResetBytes([]byte(`[[1, 2], [3 4]]`))
+		iter, err := d.ArrIter()
+		require.NoError(t, err)
+		for iter.Next() {
+			inner, err := d.ArrIter()
+			require.NoError(t, err)
+			for inner.Next() {
+				require.NoError(t, d.Skip())
+			}
+			if err := inner.Err(); err != nil {
+				e, ok := errors.Into[*DecodeError](err)
+				require.True(t, ok)
+				require.Equal(t, "/1/0", e.Pointer())
+				require.Equal(t, 1, e.Line)
+				require.Equal(t, 13, e.Column)
+				return
+			}
+		}
+		t.Fatal("error expected")
+	}))
+	t.Run("Validate", testBufferReader("[1,\n2,\n3,\n]", func(t *testing.T, d *Decoder) {
+		err := d.Validate()
+		e, ok := errors.Into[*DecodeError](err)
+		require.True(t, ok)
+		require.Empty(t, e.Path)
+		require.Equal(t, 4, e.Line)
+		require.Equal(t, 1, e.Column)
+	}))
+	t.Run("PointerEscape", func(t *testing.T) {
+		e := &DecodeError{Path: []string{"a/b", "m~n", ""}}
+		require.Equal(t, "/a~1b/m~0n/", e.Pointer())
+	})
+}
+
+func TestDecoder_position(t *testing.T) {
+	const input = "[\n1,\n22,\n333\n]"
+	d := Decode(strings.NewReader(input), 1)
+	// Read the whole input, so buffer is overwritten multiple times.
+	require.NoError(t, d.Validate())
+	for offset, expected := range [][2]int{
+		{1, 1}, {1, 2}, {2, 1}, {2, 2}, {2, 3}, {3, 1},
+	} {
+		if offset >= d.streamOffset {
+			line, col := d.position(offset)
+			require.Equal(t, expected, [2]int{line, col}, offset)
+		}
+	}
+	line, col := d.position(len(input) - 1)
+	require.Equal(t, 5, line)
+	require.Equal(t, 1, col)
+}
+
+func TestDecodeError_refill(t *testing.T) {
+	// Reader returns input in chunks of given size, so bad byte of token is
+	// in buffer that is discarded by next read.
+	chunked := func(input string, size int) io.Reader {
+		var readers []io.Reader
+		for len(input) > size {
+			readers = append(readers, strings.NewReader(input[:size]))
+			input = input[size:]
+		}
+		return io.MultiReader(append(readers, strings.NewReader(input))...)
+	}
+	decode := func(d *Decoder) *DecodeError {
+		err := d.Arr(func(d *Decoder) error {
+			_, err := d.Any()
+			return err
+		})
+		e, ok := errors.Into[*DecodeError](err)
+		require.True(t, ok, "%+v", err)
+		return e
+	}
+	for _, input := range []string{
+		"[\nn\nul]",
+		"[\n\nn\nul]",
+		"[\nt\nrue]",
+		"[\n\"\\u1\n23\"]",
+	} {
+		expected := decode(DecodeStr(input))
+		for size := 1; size <= 4; size++ {
+			e := decode(Decode(chunked(input, size), 8))
+			require.Equal(t, expected.Offset, e.Offset, "%q: %d", input, size)
+			require.Equal(t, expected.Line, e.Line, "%q: %d", input, size)
+			require.Equal(t, expected.Column, e.Column, "%q: %d", input, size)
+		}
+	}
+
+	// Only two line starts before buffer are known, column is clamped.
+	e := decode(Decode(chunked("[\n\"\\u\n\n123\"]", 4), 8))
+	require.Equal(t, 5, e.Offset)
+	require.Equal(t, 2, e.Line)
+	require.Equal(t, 1, e.Column)
+}
@@ -47,6 +47,10 @@ func init() {
 
 // Float32 reads float32 value.
 func (d *Decoder) Float32() (float32, error) {
+	if d.relaxed {
+		v, err := d.relaxedFloat(32)
+		return float32(v), err
+	}
 	c, err := d.more()
 	if err != nil {
 		return 0, err
@@ -147,6 +151,9 @@ NonDecimalLoop:
 
 // Float64 read float64
 func (d *Decoder) Float64() (float64, error) {
+	if d.relaxed {
+		return d.relaxedFloat(64)
+	}
 	c, err := d.more()
 	if err != nil {
 		return 0, err
@@ -58,7 +58,9 @@ func (d *Decoder) number() ([]byte, error) {
 	return d.buf[start:d.tail], nil
 }
 
+// numberAppend appends number literal to b.
 func (d *Decoder) numberAppend(b []byte) ([]byte, error) {
+	start := len(b)
 	for {
 		r, err := d.number()
 		if err != nil {
@@ -66,6 +68,9 @@ func (d *Decoder) numberAppend(b []byte) ([]byte, error) {
 		}
 
 		b = append(b, r...)
+		if err := d.checkNumLen(len(b) - start); err != nil {
+			return b, err
+		}
 		if d.head != d.tail {
 			return b, nil
 		}
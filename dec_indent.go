@@ -0,0 +1,219 @@
+package jx
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/go-faster/errors"
+)
+
+// Compact reads next value and writes it to w without insignificant
+// whitespace.
+//
+// Number literals and strings, including escapes, are written as-is.
+func (d *Decoder) Compact(w *Writer) error {
+	f := reformatter{w: w}
+	return d.decodeErr(f.value(d, 0))
+}
+
+// Indent reads next value and writes it to w, beginning each element of
+// object or array on a new indented line that starts with prefix followed by
+// one or more copies of indent according to the nesting depth, like
+// json.Indent.
+//
+// Number literals and strings, including escapes, are written as-is.
+func (d *Decoder) Indent(w *Writer, prefix, indent string) error {
+	f := reformatter{
+		w:      w,
+		prefix: prefix,
+		indent: indent,
+		pretty: true,
+	}
+	return d.decodeErr(f.value(d, 0))
+}
+
+// Compact reads single json value from src and writes it to dst without
+// insignificant whitespace.
+func Compact(dst io.Writer, src io.Reader) error {
+	return reformat(dst, src, func(d *Decoder, w *Writer) error {
+		return d.Compact(w)
+	})
+}
+
+// Indent reads single json value from src and writes its indented form to
+// dst. See Decoder.Indent.
+func Indent(dst io.Writer, src io.Reader, prefix, indent string) error {
+	return reformat(dst, src, func(d *Decoder, w *Writer) error {
+		return d.Indent(w, prefix, indent)
+	})
+}
+
+func reformat(dst io.Writer, src io.Reader, f func(d *Decoder, w *Writer) error) error {
+	d := GetDecoder()
+	defer PutDecoder(d)
+	d.Reset(src)
+
+	w := Writer{Buf: make([]byte, 0, encoderBufSize)}
+	w.ResetWriter(dst)
+
+	if err := f(d, &w); err != nil {
+		return err
+	}
+	if err := d.Skip(); err != io.EOF {
+		return d.decodeErr(errors.Wrap(err, "unexpected trialing data"))
+	}
+	return w.Close()
+}
+
+type reformatter struct {
+	w      *Writer
+	prefix string
+	indent string
+	pretty bool
+}
+
+// fail returns write error.
+func (f *reformatter) fail() error {
+	if err := f.w.Err(); err != nil {
+		return err
+	}
+	return errors.New("write failed")
+}
+
+// newline writes new line and indentation for given level.
+func (f *reformatter) newline(level int) bool {
+	if !f.pretty {
+		return false
+	}
+	fail := f.w.byte('\n') || f.w.RawStr(f.prefix)
+	for i := 0; i < level; i++ {
+		fail = fail || f.w.RawStr(f.indent)
+	}
+	return fail
+}
+
+func (f *reformatter) value(d *Decoder, level int) error {
+	c, err := d.next()
+	if err != nil {
+		return err
+	}
+	switch c {
+	case '{':
+		return f.container(d, level, c, '}')
+	case '[':
+		return f.container(d, level, c, ']')
+	default:
+		d.unread()
+		raw, err := d.rawTo(&d.tokenRaw)
+		if err != nil {
+			return err
+		}
+		if f.w.Raw(raw) {
+			return f.fail()
+		}
+		return nil
+	}
+}
+
+// container reformats object or array at given nesting level, assuming that
+// start bracket was consumed.
+func (f *reformatter) container(d *Decoder, level int, start, end byte) error {
+	if err := d.incDepth(); err != nil {
+		return err
+	}
+	if f.w.byte(start) {
+		return f.fail()
+	}
+	c, err := d.more()
+	if err != nil {
+		return err
+	}
+	if c == end {
+		if f.w.byte(end) {
+			return f.fail()
+		}
+		return d.decDepth()
+	}
+	d.unread()
+
+	frame := d.pushPath(start == '{')
+	for {
+		if f.newline(level + 1) {
+			return f.fail()
+		}
+		if start == '{' {
+			if err := f.key(d, frame); err != nil {
+				return err
+			}
+		}
+		if err := f.value(d, level+1); err != nil {
+			return err
+		}
+
+		c, err := d.more()
+		if err != nil {
+			return err
+		}
+		switch c {
+		case ',':
+			if f.w.byte(',') {
+				return f.fail()
+			}
+			d.path[frame].index++
+		case end:
+			d.popPath(frame)
+			if f.newline(level) || f.w.byte(end) {
+				return f.fail()
+			}
+			return d.decDepth()
+		default:
+			return badToken(c, d.offset()-1)
+		}
+	}
+}
+
+// key reformats object key and colon.
+func (f *reformatter) key(d *Decoder, frame int) error {
+	if err := d.consume('"'); err != nil {
+		return errors.Wrap(err, `'"' expected`)
+	}
+	d.unread()
+	raw, err := d.rawTo(&d.tokenRaw)
+	if err != nil {
+		return errors.Wrap(err, "field name")
+	}
+	if f.w.Raw(raw) {
+		return f.fail()
+	}
+	key := raw[1 : len(raw)-1]
+	switch {
+	case bytes.IndexByte(key, '\\') >= 0:
+		// Decode escaped key for path, like ObjBytes.
+		buf := d.path[frame].key[:0]
+		if d.reader == nil {
+			// Previous key may reference input.
+			buf = nil
+		}
+		s := d.sub(raw)
+		v, err := s.str(value{buf: buf})
+		if err != nil {
+			return errors.Wrap(err, "field name")
+		}
+		key = v.buf
+	case d.reader != nil:
+		// Raw references internal buffer that will be overwritten.
+		key = append(d.path[frame].key[:0], key...)
+	}
+	d.path[frame].key = key
+	if err := d.consume(':'); err != nil {
+		return errors.Wrap(err, `":" expected`)
+	}
+	fail := f.w.byte(':')
+	if f.pretty {
+		fail = fail || f.w.byte(' ')
+	}
+	if fail {
+		return f.fail()
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package jx
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_Compact(t *testing.T) {
+	for _, tt := range []struct {
+		Input  string
+		Expect string
+	}{
+		{`1`, `1`},
+		{` { "a" : [ 1 , 2.50 , 1e10 ] , "b" : { } , "c" : [ ] } `, `{"a":[1,2.50,1e10],"b":{},"c":[]}`},
+		{`["A\n\/", "🌍", "a b"]`, `["A\n\/","🌍","a b"]`},
+		{"[\n\ttrue,\n\tfalse,\n\tnull\n]", `[true,false,null]`},
+		{`{"\"key\"": -0.0e-0}`, `{"\"key\"":-0.0e-0}`},
+	} {
+		tt := tt
+		t.Run(tt.Input, testBufferReader(tt.Input, func(t *testing.T, d *Decoder) {
+			var w Writer
+			require.NoError(t, d.Compact(&w))
+			require.Equal(t, tt.Expect, w.String())
+		}))
+	}
+}
+
+func TestDecoder_Indent(t *testing.T) {
+	const input = `{"a":[1,"x",{"b":null}],"e":{},"f":[],"g":{"h":[[]]}}`
+	t.Run("Simple", testBufferReader(input, func(t *testing.T, d *Decoder) {
+		var w Writer
+		require.NoError(t, d.Indent(&w, "", "  "))
+		require.Equal(t, `{
+  "a": [
+    1,
+    "x",
+    {
+      "b": null
+    }
+  ],
+  "e": {},
+  "f": [],
+  "g": {
+    "h": [
+      []
+    ]
+  }
+}`, w.String())
+	}))
+	t.Run("Prefix", testBufferReader(`[1,{"a":2}]`, func(t *testing.T, d *Decoder) {
+		var w Writer
+		require.NoError(t, d.Indent(&w, "> ", "\t"))
+		require.Equal(t, "[\n> \t1,\n> \t{\n> \t\t\"a\": 2\n> \t}\n> ]", w.String())
+	}))
+	t.Run("Nested", func(t *testing.T) {
+		d := DecodeStr(`{"a":{"b":[1]}}`)
+		var w Writer
+		require.NoError(t, d.Obj(func(d *Decoder, key string) error {
+			return d.Indent(&w, "", " ")
+		}))
+		require.Equal(t, "{\n \"b\": [\n  1\n ]\n}", w.String())
+	})
+}
+
+func TestIndent(t *testing.T) {
+	runTestdata(t.Fatal, func(name string, data []byte) {
+		t.Run(name, func(t *testing.T) {
+			// Trailing whitespace is copied by encoding/json.
+			data = bytes.TrimSpace(data)
+			for _, prefix := range []string{"", "//"} {
+				var expect bytes.Buffer
+				require.NoError(t, json.Indent(&expect, data, prefix, "\t"))
+
+				var got bytes.Buffer
+				require.NoError(t, Indent(&got, iotest.HalfReader(bytes.NewReader(data)), prefix, "\t"))
+				require.Equal(t, expect.String(), got.String())
+			}
+
+			var expect bytes.Buffer
+			require.NoError(t, json.Compact(&expect, data))
+			var got bytes.Buffer
+			require.NoError(t, Compact(&got, bytes.NewReader(data)))
+			require.Equal(t, expect.String(), got.String())
+		})
+	})
+}
+
+func TestCompact_Errors(t *testing.T) {
+	for _, input := range []string{
+		``,
+		`{`,
+		`{"a"}`,
+		`{"a":1,}`,
+		`[1 2]`,
+		`[1,]`,
+		`{1:2}`,
+		`"unterminated`,
+		`[1] 2`,
+		`tru`,
+	} {
+		input := input
+		t.Run(input, func(t *testing.T) {
+			require.Error(t, Compact(io.Discard, strings.NewReader(input)))
+			require.Error(t, Indent(io.Discard, strings.NewReader(input), "", " "))
+		})
+	}
+	t.Run("Path", func(t *testing.T) {
+		err := Compact(io.Discard, strings.NewReader(`{"a":[1,2,{"b":x}]}`))
+		var decErr *DecodeError
+		require.ErrorAs(t, err, &decErr)
+		require.Equal(t, "/a/2/b", decErr.Pointer())
+	})
+	t.Run("EscapedKey", testBufferReader(`{"\u0041":{"b\n":x}}`, func(t *testing.T, d *Decoder) {
+		var w Writer
+		err := d.Compact(&w)
+		var decErr *DecodeError
+		require.ErrorAs(t, err, &decErr)
+		require.Equal(t, []string{"A", "b\n"}, decErr.Path)
+	}))
+	t.Run("Write", func(t *testing.T) {
+		err := Compact(&errWriter{err: io.ErrClosedPipe}, strings.NewReader(`[1]`))
+		require.ErrorIs(t, err, io.ErrClosedPipe)
+		err = Indent(&errWriter{err: io.ErrClosedPipe}, strings.NewReader(strings.Repeat("[", 500)), "", "    ")
+		require.ErrorIs(t, err, io.ErrClosedPipe)
+	})
+}
+
+func BenchmarkCompact(b *testing.B) {
+	var w Writer
+	d := GetDecoder()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchData)))
+
+	for i := 0; i < b.N; i++ {
+		w.Reset()
+		d.ResetBytes(benchData)
+		if err := d.Compact(&w); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package jx
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// Structural index is fast path of Skip for objects and arrays.
+//
+// Input is processed in blocks of 64 bytes: indexMasks classifies bytes of
+// block into bitmasks, which are used to find strings and tokens outside of
+// them, like in simdjson. Then grammar is checked only at token positions.
+//
+// Index does not report errors: if value is invalid, does not fit into
+// buffer or some option requires checks that are not implemented by index,
+// value is skipped by regular Skip, which reports the error.
+
+const indexBlock = 64
+
+// indexPadding is whitespace that pads last block.
+const indexPadding = "                                                                "
+
+// indexMasksGeneric is portable implementation of indexMasks.
+func indexMasksGeneric(b *[indexBlock]byte) (quote, backslash, ctrl, space, structural uint64) {
+	for i, c := range b {
+		class := uint64(indexClass[c])
+		quote |= class & indexQuote << i
+		backslash |= class & indexBackslash >> 1 << i
+		ctrl |= class & indexCtrl >> 2 << i
+		space |= class & indexSpace >> 3 << i
+		structural |= class & indexStructural >> 4 << i
+	}
+	return quote, backslash, ctrl, space, structural
+}
+
+// Byte classes of indexClass.
+const (
+	indexQuote = 1 << iota
+	indexBackslash
+	indexCtrl
+	indexSpace
+	indexStructural
+)
+
+var indexClass = func() (t [256]byte) {
+	for c := 0; c < ' '; c++ {
+		t[c] = indexCtrl
+	}
+	t['"'] = indexQuote
+	t['\\'] = indexBackslash
+	for _, c := range []byte{' ', '\t', '\n', '\r'} {
+		t[c] |= indexSpace
+	}
+	for _, c := range []byte{'{', '}', '[', ']', ':', ','} {
+		t[c] = indexStructural
+	}
+	return t
+}()
+
+// Grammar states of index.
+const (
+	indexValue      = iota // value
+	indexValueOrEnd        // value or "]"
+	indexKey               // key
+	indexKeyOrEnd          // key or "}"
+	indexColon             // ":"
+	indexNext              // "," or end of object or array
+)
+
+// skipIndex skips object or array, starting at d.head-1, using structural
+// index.
+//
+// Returns false if value should be skipped by Skip.
+func (d *Decoder) skipIndex() bool {
+	start := d.head - 1
+	if !indexEnabled || d.streamOffset+start < d.indexStop || !d.canIndex() {
+		return false
+	}
+	end, ok := d.index(start)
+	if !ok {
+		// Do not try to index values that start before position where index
+		// stopped, otherwise nested values would be scanned again.
+		d.indexStop = d.streamOffset + end
+		return false
+	}
+	d.head = end
+	return true
+}
+
+// canIndex reports whether options allow skipping by index.
+func (d *Decoder) canIndex() bool {
+	l := d.limits
+	return !d.relaxed &&
+		!d.disallowDupKeys &&
+		d.utf8 != UTF8Strict &&
+		l.MaxStrLen <= 0 &&
+		l.MaxNumLen <= 0 &&
+		l.MaxObjKeys <= 0 &&
+		l.MaxArrElems <= 0
+}
+
+// index checks object or array at buf[start] and returns its end.
+//
+// If value is invalid or incomplete, returns position where check stopped
+// and false.
+func (d *Decoder) index(start int) (int, bool) {
+	var (
+		buf = d.buf[:d.tail]
+		max = d.limits.MaxDepth
+
+		stack = d.indexStack[:0] // bit is set for object
+		depth int
+		state = indexValue
+
+		escaped   uint64 // first byte of next block is escaped
+		inString  uint64 // next block starts in string
+		inScalar  uint64 // next block starts in number or literal
+		block     [indexBlock]byte
+		blockData *[indexBlock]byte
+	)
+	if max <= 0 {
+		max = maxDepth
+	}
+	max -= d.depth
+
+	for base := start; base < len(buf); base += indexBlock {
+		if rest := buf[base:]; len(rest) >= indexBlock {
+			blockData = (*[indexBlock]byte)(rest)
+		} else {
+			// Pad last block with spaces.
+			n := copy(block[:], rest)
+			copy(block[n:], indexPadding)
+			blockData = &block
+		}
+		quote, backslash, ctrl, space, structural := indexMasks(blockData)
+
+		// Find escaped characters and strings.
+		var esc uint64
+		if backslash|escaped != 0 {
+			esc = escapedMask(backslash, &escaped)
+			quote &^= esc
+		}
+		str := prefixXor(quote) ^ inString
+		inString = uint64(int64(str) >> 63)
+		if ctrl&str != 0 {
+			return base + bits.TrailingZeros64(ctrl&str), false
+		}
+		for e := esc & str; e != 0; e &= e - 1 {
+			if i := base + bits.TrailingZeros64(e); i >= len(buf) || !indexEscape(buf, i) {
+				return i, false
+			}
+		}
+
+		// Tokens are structural characters and starts of strings, numbers
+		// and literals.
+		scalar := ^(str | quote | space | structural)
+		tokens := structural&^str | quote&str | scalar&^(scalar<<1|inScalar)
+		inScalar = scalar >> 63
+
+		for ; tokens != 0; tokens &= tokens - 1 {
+			i := base + bits.TrailingZeros64(tokens)
+			c := buf[i]
+			switch state {
+			case indexNext:
+				obj := stack[(depth-1)/64]&(1<<((depth-1)%64)) != 0
+				switch {
+				case c == ',' && obj:
+					state = indexKey
+				case c == ',':
+					state = indexValue
+				case c == '}' && obj, c == ']' && !obj:
+					depth--
+					if depth == 0 {
+						return i + 1, true
+					}
+				default:
+					return i, false
+				}
+				continue
+			case indexKey, indexKeyOrEnd:
+				switch {
+				case c == '"':
+					state = indexColon
+				case c == '}' && state == indexKeyOrEnd:
+					depth--
+					if depth == 0 {
+						return i + 1, true
+					}
+					state = indexNext
+				default:
+					return i, false
+				}
+				continue
+			case indexColon:
+				if c != ':' {
+					return i, false
+				}
+				state = indexValue
+				continue
+			case indexValueOrEnd:
+				if c == ']' {
+					depth--
+					if depth == 0 {
+						return i + 1, true
+					}
+					state = indexNext
+					continue
+				}
+			}
+
+			// Value.
+			state = indexNext
+			switch c {
+			case '{', '[':
+				if depth >= max {
+					return i, false
+				}
+				w, bit := depth/64, uint64(1)<<(depth%64)
+				if w == len(stack) {
+					stack = append(stack, 0)
+					d.indexStack = stack
+				}
+				if c == '{' {
+					stack[w] |= bit
+					state = indexKeyOrEnd
+				} else {
+					stack[w] &^= bit
+					state = indexValueOrEnd
+				}
+				depth++
+			case '"':
+				// Checked by masks.
+			case 'n':
+				if !indexLiteral(buf, i, "null") {
+					return i, false
+				}
+			case 't':
+				if !indexLiteral(buf, i, "true") {
+					return i, false
+				}
+			case 'f':
+				if !indexLiteral(buf, i, "false") {
+					return i, false
+				}
+			default:
+				if !indexNumber(buf, i) {
+					return i, false
+				}
+			}
+		}
+	}
+	return len(buf), false
+}
+
+// escapedMask returns mask of characters escaped by backslashes.
+//
+// Carry is set if first character of next block is escaped.
+func escapedMask(backslash uint64, carry *uint64) uint64 {
+	escaped := *carry
+	backslash &^= escaped
+	*carry = 0
+	for backslash != 0 {
+		i := bits.TrailingZeros64(backslash)
+		if i == indexBlock-1 {
+			*carry = 1
+			break
+		}
+		escaped |= 1 << (i + 1)
+		// Escaped backslash does not escape next character.
+		backslash &^= 3 << i
+	}
+	return escaped
+}
+
+// prefixXor returns mask where bit is set if odd count of bits is set at or
+// before it, so that quotes are converted to strings.
+func prefixXor(x uint64) uint64 {
+	x ^= x << 1
+	x ^= x << 2
+	x ^= x << 4
+	x ^= x << 8
+	x ^= x << 16
+	x ^= x << 32
+	return x
+}
+
+// indexEscape checks escaped character at buf[i].
+func indexEscape(buf []byte, i int) bool {
+	switch escapedStrSet[buf[i]] {
+	case 0:
+		return false
+	case 'u':
+		if i+4 >= len(buf) {
+			return false
+		}
+		for _, h := range buf[i+1 : i+5] {
+			if hexSet[h] == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// indexLiteral checks that buf[i:] starts with literal followed by token
+// or whitespace.
+func indexLiteral(buf []byte, i int, lit string) bool {
+	const delim = indexQuote | indexSpace | indexStructural
+	end := i + len(lit)
+	return end < len(buf) && string(buf[i:end]) == lit && indexClass[buf[end]]&delim != 0
+}
+
+// indexNumber checks that buf[i:] starts with number followed by byte
+// accepted by skipNumber.
+func indexNumber(buf []byte, i int) bool {
+	n := len(buf)
+	if buf[i] == '-' {
+		i++
+	}
+	// Integer part.
+	switch {
+	case i >= n:
+		return false
+	case buf[i] == '0':
+		i++
+	case isDigit(buf[i]):
+		i = indexDigits(buf, i+1)
+	default:
+		return false
+	}
+	// Fraction.
+	if i < n && buf[i] == '.' {
+		i++
+		if i >= n || !isDigit(buf[i]) {
+			return false
+		}
+		i = indexDigits(buf, i+1)
+	}
+	// Exponent.
+	if i < n && (buf[i] == 'e' || buf[i] == 'E') {
+		i++
+		if i < n && (buf[i] == '-' || buf[i] == '+') {
+			i++
+		}
+		if i >= n || !isDigit(buf[i]) {
+			return false
+		}
+		i = indexDigits(buf, i+1)
+	}
+	const closerTag byte = 2
+	return i < n && skipNumberSet[buf[i]] == closerTag
+}
+
+func isDigit(c byte) bool {
+	return c-'0' < 10
+}
+
+// indexDigits returns position of first non-digit in buf[i:].
+func indexDigits(buf []byte, i int) int {
+	for ; i+8 <= len(buf); i += 8 {
+		x := binary.LittleEndian.Uint64(buf[i:])
+		// Byte is zero if it is in range 0x30-0x39.
+		m := (x&0xf0f0f0f0f0f0f0f0 | (x+0x0606060606060606)&0xf0f0f0f0f0f0f0f0>>4) ^ 0x3333333333333333
+		if m != 0 {
+			return i + bits.TrailingZeros64(m)/8
+		}
+	}
+	for ; i < len(buf) && isDigit(buf[i]); i++ {
+	}
+	return i
+}
@@ -0,0 +1,14 @@
+//go:build amd64 && !purego
+
+package jx
+
+// indexEnabled reports whether Skip uses structural index.
+const indexEnabled = true
+
+// indexMasks returns bitmasks of quotes, backslashes, control characters,
+// whitespace and structural characters ("{}[]:,") of block.
+//
+// Implemented with SSE2, which is available on every amd64 CPU.
+//
+//go:noescape
+func indexMasks(b *[indexBlock]byte) (quote, backslash, ctrl, space, structural uint64)
@@ -0,0 +1,108 @@
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+#define BROADCAST(name, c) \
+	DATA name<>+0(SB)/8, $c; \
+	DATA name<>+8(SB)/8, $c; \
+	GLOBL name<>(SB), RODATA|NOPTR, $16
+
+BROADCAST(indexQuote, 0x2222222222222222)
+BROADCAST(indexBackslash, 0x5c5c5c5c5c5c5c5c)
+BROADCAST(indexCtrl, 0x1f1f1f1f1f1f1f1f)
+BROADCAST(indexSpace, 0x2020202020202020)
+BROADCAST(indexTab, 0x0909090909090909)
+BROADCAST(indexNewline, 0x0a0a0a0a0a0a0a0a)
+BROADCAST(indexReturn, 0x0d0d0d0d0d0d0d0d)
+BROADCAST(indexOpen, 0x7b7b7b7b7b7b7b7b)
+BROADCAST(indexClose, 0x7d7d7d7d7d7d7d7d)
+BROADCAST(indexColon, 0x3a3a3a3a3a3a3a3a)
+BROADCAST(indexComma, 0x2c2c2c2c2c2c2c2c)
+
+// MASK sets bits of 16 bytes of X1 to R, starting at bit CX.
+#define MASK(R) \
+	PMOVMSKB X1, AX; \
+	SHLQ     CX, AX; \
+	ORQ      AX, R
+
+// func indexMasks(b *[indexBlock]byte) (quote, backslash, ctrl, space, structural uint64)
+TEXT ·indexMasks(SB), NOSPLIT, $0-48
+	MOVQ b+0(FP), SI
+
+	MOVOU indexQuote<>(SB), X3
+	MOVOU indexBackslash<>(SB), X4
+	MOVOU indexCtrl<>(SB), X5
+	MOVOU indexSpace<>(SB), X6
+	MOVOU indexTab<>(SB), X7
+	MOVOU indexNewline<>(SB), X8
+	MOVOU indexReturn<>(SB), X9
+	MOVOU indexOpen<>(SB), X10
+	MOVOU indexClose<>(SB), X11
+	MOVOU indexColon<>(SB), X12
+	MOVOU indexComma<>(SB), X13
+
+	XORQ R8, R8
+	XORQ R9, R9
+	XORQ R10, R10
+	XORQ R11, R11
+	XORQ R12, R12
+	XORQ CX, CX
+
+loop:
+	MOVOU (SI)(CX*1), X0
+
+	// Quote.
+	MOVOU   X0, X1
+	PCMPEQB X3, X1
+	MASK(R8)
+
+	// Backslash.
+	MOVOU   X0, X1
+	PCMPEQB X4, X1
+	MASK(R9)
+
+	// Control character, min(c, 0x1f) == c.
+	MOVOU   X0, X1
+	PMINUB  X5, X1
+	PCMPEQB X0, X1
+	MASK(R10)
+
+	// Whitespace.
+	MOVOU   X0, X1
+	PCMPEQB X6, X1
+	MOVOU   X0, X2
+	PCMPEQB X7, X2
+	POR     X2, X1
+	MOVOU   X0, X2
+	PCMPEQB X8, X2
+	POR     X2, X1
+	MOVOU   X0, X2
+	PCMPEQB X9, X2
+	POR     X2, X1
+	MASK(R11)
+
+	// Structural, c|0x20 maps "[" to "{" and "]" to "}".
+	MOVOU   X0, X2
+	POR     X6, X2
+	MOVOU   X2, X1
+	PCMPEQB X10, X1
+	PCMPEQB X11, X2
+	POR     X2, X1
+	MOVOU   X0, X2
+	PCMPEQB X12, X2
+	POR     X2, X1
+	MOVOU   X0, X2
+	PCMPEQB X13, X2
+	POR     X2, X1
+	MASK(R12)
+
+	ADDQ $16, CX
+	CMPQ CX, $64
+	JB   loop
+
+	MOVQ R8, quote+8(FP)
+	MOVQ R9, backslash+16(FP)
+	MOVQ R10, ctrl+24(FP)
+	MOVQ R11, space+32(FP)
+	MOVQ R12, structural+40(FP)
+	RET
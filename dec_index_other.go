@@ -0,0 +1,14 @@
+//go:build !amd64 || purego
+
+package jx
+
+// indexEnabled reports whether Skip uses structural index.
+//
+// Index with portable indexMasks is slower than regular Skip.
+const indexEnabled = false
+
+// indexMasks returns bitmasks of quotes, backslashes, control characters,
+// whitespace and structural characters ("{}[]:,") of block.
+func indexMasks(b *[indexBlock]byte) (quote, backslash, ctrl, space, structural uint64) {
+	return indexMasksGeneric(b)
+}
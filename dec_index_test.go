@@ -0,0 +1,157 @@
+package jx
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexMasks(t *testing.T) {
+	var b [indexBlock]byte
+	check := func() {
+		t.Helper()
+		q, bs, ctrl, space, st := indexMasks(&b)
+		eq, ebs, ectrl, espace, est := indexMasksGeneric(&b)
+		require.Equal(t, eq, q, "quote")
+		require.Equal(t, ebs, bs, "backslash")
+		require.Equal(t, ectrl, ctrl, "ctrl")
+		require.Equal(t, espace, space, "space")
+		require.Equal(t, est, st, "structural")
+	}
+	for c := 0; c < 256; c++ {
+		for i := range b {
+			b[i] = byte(c + i)
+		}
+		check()
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		rnd.Read(b[:])
+		check()
+	}
+
+	copy(b[:], `{"a":[1,2],"b\"":"\\"}`+"\t\r\n ")
+	at := func(pos ...int) (m uint64) {
+		for _, p := range pos {
+			m |= 1 << p
+		}
+		return m
+	}
+	const mask = 1<<26 - 1
+	q, bs, ctrl, space, st := indexMasksGeneric(&b)
+	require.Equal(t, at(1, 3, 11, 14, 15, 17, 20), q&mask)
+	require.Equal(t, at(13, 18, 19), bs&mask)
+	require.Equal(t, at(22, 23, 24), ctrl&mask)
+	require.Equal(t, at(22, 23, 24, 25), space&mask)
+	require.Equal(t, at(0, 4, 5, 7, 9, 10, 16, 21), st&mask)
+}
+
+func TestDecoder_index(t *testing.T) {
+	skip := func(input string, index bool) (int, error) {
+		d := DecodeStr(input)
+		if !index {
+			d.indexStop = len(input) + 1
+		}
+		err := d.Skip()
+		return d.head, err
+	}
+	test := func(t *testing.T, input string) {
+		t.Helper()
+		expectedHead, expectedErr := skip(input, false)
+		head, err := skip(input, true)
+		if expectedErr != nil {
+			require.EqualError(t, err, expectedErr.Error(), "%q", input)
+			return
+		}
+		require.NoError(t, err, "%q", input)
+		require.Equal(t, expectedHead, head, "%q", input)
+	}
+
+	t.Run("Cases", func(t *testing.T) {
+		for _, set := range [][]string{testObjs, testArrs} {
+			for _, input := range set {
+				test(t, input)
+			}
+		}
+		for _, input := range []string{
+			`[true\]`,
+			`[true` + "\x01" + `]`,
+			`[null"a"]`,
+			`[1:2]`,
+			`[1"a"]`,
+			`[1\"]`,
+			`["a\"]`,
+			`["a\\"]`,
+			`["\u00e9\uD83D\uDE00"]`,
+			`["\u00g0"]`,
+			`["\u00`,
+			`["\x"]`,
+			`["a` + "\t" + `"]`,
+			`{"a":1:2}`,
+			`{"a" 1}`,
+			`{"a":1,}`,
+			`[1,]`,
+			`[,1]`,
+			`[-]`,
+			`[-0]`,
+			`[-01]`,
+			`[1.]`,
+			`[1.e5]`,
+			`[1e]`,
+			`[1e+]`,
+			`[1E-5]`,
+			`[1.5e+10,0.0]`,
+			`[12345678901234567890]`,
+			`[1234567890123456789x]`,
+			`[]]`,
+			`{}}`,
+			`[}`,
+			`{]`,
+			`[{]}`,
+			`[[]`,
+			`["` + strings.Repeat(`\\`, 40) + `"]`,
+			`["` + strings.Repeat(`\\`, 40) + `\"]`,
+			`[` + strings.Repeat(`"\"",`, 30) + `1]`,
+		} {
+			test(t, input)
+			// Shift input to cross block boundary.
+			for n := 1; n < indexBlock; n += 7 {
+				test(t, strings.Repeat(" ", n)+input)
+				test(t, `[`+strings.Repeat(" ", n)+input+`]`)
+			}
+		}
+	})
+	t.Run("Testdata", func(t *testing.T) {
+		runTestdata(t.Fatal, func(name string, data []byte) {
+			d := DecodeBytes(data)
+			switch d.Next() {
+			case Object, Array:
+			default:
+				return
+			}
+			_, ok := d.index(d.head)
+			require.True(t, ok, name)
+			test(t, string(data))
+		})
+	})
+	t.Run("Depth", func(t *testing.T) {
+		input := strings.Repeat("[", 300) + strings.Repeat("]", 300)
+		test(t, input)
+
+		d := DecodeStr(input)
+		d.SetLimits(Limits{MaxDepth: 299})
+		require.ErrorIs(t, d.Skip(), ErrMaxDepth)
+		d.ResetBytes([]byte(input))
+		d.SetLimits(Limits{MaxDepth: 300})
+		require.NoError(t, d.Skip())
+	})
+	t.Run("Reader", func(t *testing.T) {
+		input := `[` + strings.Repeat(`{"a":[1,2,"3"],"b":null},`, 100) + `true]`
+		for _, size := range []int{1, 16, 64, 512, 4096} {
+			d := Decode(strings.NewReader(input), size)
+			require.NoError(t, d.Validate())
+		}
+	})
+}
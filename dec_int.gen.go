@@ -21,6 +21,10 @@ const (
 
 // UInt8 reads uint8.
 func (d *Decoder) UInt8() (uint8, error) {
+	if d.relaxed {
+		v, err := d.relaxedUIntMax(math.MaxUint8)
+		return uint8(v), err
+	}
 	c, err := d.more()
 	if err != nil {
 		return 0, err
@@ -153,6 +157,10 @@ func (d *Decoder) readUInt8(c byte) (uint8, error) {
 
 // Int8 reads int8.
 func (d *Decoder) Int8() (int8, error) {
+	if d.relaxed {
+		v, err := d.relaxedIntMax(math.MaxInt8)
+		return int8(v), err
+	}
 	c, err := d.more()
 	if err != nil {
 		return 0, err
@@ -183,6 +191,10 @@ func (d *Decoder) Int8() (int8, error) {
 
 // UInt16 reads uint16.
 func (d *Decoder) UInt16() (uint16, error) {
+	if d.relaxed {
+		v, err := d.relaxedUIntMax(math.MaxUint16)
+		return uint16(v), err
+	}
 	c, err := d.more()
 	if err != nil {
 		return 0, err
@@ -358,6 +370,10 @@ func (d *Decoder) readUInt16(c byte) (uint16, error) {
 
 // Int16 reads int16.
 func (d *Decoder) Int16() (int16, error) {
+	if d.relaxed {
+		v, err := d.relaxedIntMax(math.MaxInt16)
+		return int16(v), err
+	}
 	c, err := d.more()
 	if err != nil {
 		return 0, err
@@ -388,6 +404,10 @@ func (d *Decoder) Int16() (int16, error) {
 
 // UInt32 reads uint32.
 func (d *Decoder) UInt32() (uint32, error) {
+	if d.relaxed {
+		v, err := d.relaxedUIntMax(math.MaxUint32)
+		return uint32(v), err
+	}
 	c, err := d.more()
 	if err != nil {
 		return 0, err
@@ -661,6 +681,10 @@ func (d *Decoder) readUInt32(c byte) (uint32, error) {
 
 // Int32 reads int32.
 func (d *Decoder) Int32() (int32, error) {
+	if d.relaxed {
+		v, err := d.relaxedIntMax(math.MaxInt32)
+		return int32(v), err
+	}
 	c, err := d.more()
 	if err != nil {
 		return 0, err
@@ -691,6 +715,10 @@ func (d *Decoder) Int32() (int32, error) {
 
 // UInt64 reads uint64.
 func (d *Decoder) UInt64() (uint64, error) {
+	if d.relaxed {
+		v, err := d.relaxedUIntMax(math.MaxUint64)
+		return uint64(v), err
+	}
 	c, err := d.more()
 	if err != nil {
 		return 0, err
@@ -964,6 +992,10 @@ func (d *Decoder) readUInt64(c byte) (uint64, error) {
 
 // Int64 reads int64.
 func (d *Decoder) Int64() (int64, error) {
+	if d.relaxed {
+		v, err := d.relaxedIntMax(math.MaxInt64)
+		return int64(v), err
+	}
 	c, err := d.more()
 	if err != nil {
 		return 0, err
@@ -0,0 +1,107 @@
+package jx
+
+import "github.com/go-faster/errors"
+
+// Errors returned when Decoder limit is exceeded.
+var (
+	// ErrMaxStrLen means that string literal is longer than Limits.MaxStrLen.
+	ErrMaxStrLen = errors.New("string: maximum length")
+	// ErrMaxNumLen means that number literal is longer than Limits.MaxNumLen.
+	ErrMaxNumLen = errors.New("number: maximum length")
+	// ErrMaxObjKeys means that object has more keys than Limits.MaxObjKeys.
+	ErrMaxObjKeys = errors.New("object: maximum keys")
+	// ErrMaxArrElems means that array has more elements than Limits.MaxArrElems.
+	ErrMaxArrElems = errors.New("array: maximum elements")
+	// ErrMaxBytes means that input is longer than Limits.MaxBytes.
+	ErrMaxBytes = errors.New("input: maximum bytes")
+)
+
+// Limits of Decoder, useful for decoding untrusted input.
+//
+// Zero or negative value means no limit, except MaxDepth which defaults
+// to 10000.
+type Limits struct {
+	// MaxDepth is maximum nesting depth of objects and arrays.
+	MaxDepth int
+	// MaxStrLen is maximum length of string literal in bytes, not including
+	// quotes.
+	MaxStrLen int
+	// MaxNumLen is maximum length of number literal in bytes.
+	//
+	// Applies to skipped, raw and big numbers and to floats that are too
+	// long to be parsed by fast path. Fixed size integers are limited by
+	// their size anyway.
+	MaxNumLen int
+	// MaxObjKeys is maximum count of keys in single object.
+	MaxObjKeys int
+	// MaxArrElems is maximum count of elements in single array.
+	MaxArrElems int
+	// MaxBytes is maximum count of bytes read from input.
+	MaxBytes int
+}
+
+// SetLimits sets decoding limits.
+//
+// Limits are kept on Reset and ResetBytes, but reset on PutDecoder.
+func (d *Decoder) SetLimits(l Limits) {
+	d.limits = l
+	d.limitInput()
+}
+
+// Limits returns current decoding limits.
+func (d *Decoder) Limits() Limits {
+	return d.limits
+}
+
+// limitInput truncates buffered input to Limits.MaxBytes.
+func (d *Decoder) limitInput() {
+	max := d.limits.MaxBytes
+	if d.reader != nil || max <= 0 || d.tail <= max {
+		return
+	}
+	d.tail = max
+	if d.head > d.tail {
+		d.head = d.tail
+	}
+	d.truncated = true
+}
+
+// limitRead truncates buffer after read to Limits.MaxBytes.
+func (d *Decoder) limitRead() {
+	max := d.limits.MaxBytes
+	if max <= 0 {
+		return
+	}
+	if over := d.streamOffset + d.tail - max; over > 0 {
+		d.tail -= over
+		d.truncated = true
+	}
+}
+
+func (d *Decoder) checkStrLen(n int) error {
+	if max := d.limits.MaxStrLen; max > 0 && n > max {
+		return ErrMaxStrLen
+	}
+	return nil
+}
+
+func (d *Decoder) checkNumLen(n int) error {
+	if max := d.limits.MaxNumLen; max > 0 && n > max {
+		return ErrMaxNumLen
+	}
+	return nil
+}
+
+func (d *Decoder) checkObjKeys(n int) error {
+	if max := d.limits.MaxObjKeys; max > 0 && n > max {
+		return ErrMaxObjKeys
+	}
+	return nil
+}
+
+func (d *Decoder) checkArrElems(n int) error {
+	if max := d.limits.MaxArrElems; max > 0 && n > max {
+		return ErrMaxArrElems
+	}
+	return nil
+}
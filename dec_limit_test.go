@@ -0,0 +1,149 @@
+package jx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_SetLimits(t *testing.T) {
+	type op struct {
+		Name string
+		Do   func(d *Decoder) error
+	}
+	ops := []op{
+		{"Validate", func(d *Decoder) error { return d.Validate() }},
+		{"Crawl", crawlValue},
+		{"Any", decoderOnlyError((*Decoder).Any)},
+	}
+	for _, tt := range []struct {
+		Limits Limits
+		OK     []string
+		Fail   []string
+		Err    error
+	}{
+		{
+			Limits: Limits{MaxDepth: 2},
+			OK:     []string{`[[1]]`, `{"a":{"b":1}}`, `[{}]`},
+			Fail:   []string{`[[[1]]]`, `{"a":{"b":[]}}`, `[{"a":[1]}]`},
+			Err:    ErrMaxDepth,
+		},
+		{
+			Limits: Limits{MaxStrLen: 6},
+			OK:     []string{`"abcdef"`, `{"abcdef":"\n\n\n"}`, `["", "a"]`},
+			Fail:   []string{`"abcdefg"`, `{"abcdefg":1}`, `["abcdefgh"]`, `["a", "\n\n\n\n"]`},
+			Err:    ErrMaxStrLen,
+		},
+		{
+			Limits: Limits{MaxNumLen: 24},
+			OK:     []string{`123456789012345678901234`, `[-12.3, 1e10]`, `{"a":1.25}`},
+			Fail: []string{
+				`1234567890123456789012345`,
+				`[-1.00000000000000000000001]`,
+				`{"a":0.000000000000000000000001}`,
+			},
+			Err: ErrMaxNumLen,
+		},
+		{
+			Limits: Limits{MaxObjKeys: 2},
+			OK:     []string{`{}`, `{"a":1,"b":{"a":1,"b":2}}`, `[{"a":1},{"b":1}]`},
+			Fail:   []string{`{"a":1,"b":2,"c":3}`, `[{"a":{"a":1,"b":2,"c":3}}]`},
+			Err:    ErrMaxObjKeys,
+		},
+		{
+			Limits: Limits{MaxArrElems: 2},
+			OK:     []string{`[]`, `[1,[1,2]]`, `{"a":[1],"b":[1,2]}`},
+			Fail:   []string{`[1,2,3]`, `[[1,2,3]]`, `{"a":[1,2,3]}`},
+			Err:    ErrMaxArrElems,
+		},
+		{
+			Limits: Limits{MaxBytes: 10},
+			OK:     []string{`[1,2,3,4]`, `"123456"  `, `1234567890`},
+			Fail:   []string{`[1,2,3,4,5]`, `"1234567890"`, `1234567890 `, `{"a":"bcde"}`},
+			Err:    ErrMaxBytes,
+		},
+	} {
+		tt := tt
+		for _, o := range ops {
+			o := o
+			name := fmt.Sprintf("%+v/%s", tt.Limits, o.Name)
+			t.Run(name, func(t *testing.T) {
+				for i, input := range tt.OK {
+					t.Run(fmt.Sprintf("OK%d", i), testBufferReader(input, func(t *testing.T, d *Decoder) {
+						d.SetLimits(tt.Limits)
+						require.NoError(t, o.Do(d))
+					}))
+				}
+				for i, input := range tt.Fail {
+					t.Run(fmt.Sprintf("Fail%d", i), testBufferReader(input, func(t *testing.T, d *Decoder) {
+						d.SetLimits(tt.Limits)
+						err := o.Do(d)
+						if err == nil && o.Name != "Validate" {
+							// Check for trailing data.
+							err = d.Validate()
+						}
+						require.ErrorIs(t, err, tt.Err)
+					}))
+				}
+			})
+		}
+	}
+}
+
+func TestDecoder_LimitsScalar(t *testing.T) {
+	t.Run("Str", testBufferReader(`"`+strings.Repeat("a", 1024)+`"`, func(t *testing.T, d *Decoder) {
+		d.SetLimits(Limits{MaxStrLen: 100})
+		_, err := d.Str()
+		require.ErrorIs(t, err, ErrMaxStrLen)
+	}))
+	t.Run("StrEscaped", testBufferReader(`"\n`+strings.Repeat("a", 1024)+`"`, func(t *testing.T, d *Decoder) {
+		d.SetLimits(Limits{MaxStrLen: 100})
+		_, err := d.StrAppend([]byte("prefix"))
+		require.ErrorIs(t, err, ErrMaxStrLen)
+	}))
+	t.Run("BigInt", testBufferReader(strings.Repeat("1", 1024), func(t *testing.T, d *Decoder) {
+		d.SetLimits(Limits{MaxNumLen: 100})
+		_, err := d.BigInt()
+		require.ErrorIs(t, err, ErrMaxNumLen)
+	}))
+	t.Run("Num", testBufferReader(strings.Repeat("1", 1024), func(t *testing.T, d *Decoder) {
+		d.SetLimits(Limits{MaxNumLen: 100})
+		_, err := d.Num()
+		require.ErrorIs(t, err, ErrMaxNumLen)
+	}))
+	t.Run("Float", testBufferReader("1."+strings.Repeat("1", 1024), func(t *testing.T, d *Decoder) {
+		d.SetLimits(Limits{MaxNumLen: 100})
+		_, err := d.Float64()
+		require.ErrorIs(t, err, ErrMaxNumLen)
+	}))
+	t.Run("NumAppend", testBufferReader(`12345`, func(t *testing.T, d *Decoder) {
+		// Only literal is counted, not bytes appended to.
+		d.SetLimits(Limits{MaxNumLen: 5})
+		b, err := d.numberAppend([]byte("prefix"))
+		require.NoError(t, err)
+		require.Equal(t, "prefix12345", string(b))
+	}))
+	t.Run("Bool", testBufferReader(`true`, func(t *testing.T, d *Decoder) {
+		d.SetLimits(Limits{MaxBytes: 3})
+		_, err := d.Bool()
+		require.ErrorIs(t, err, ErrMaxBytes)
+	}))
+}
+
+func TestDecoder_LimitsReset(t *testing.T) {
+	d := GetDecoder()
+	l := Limits{MaxDepth: 1, MaxBytes: 2}
+	d.SetLimits(l)
+	require.Equal(t, l, d.Limits())
+
+	d.ResetBytes([]byte(`[[]]`))
+	require.ErrorIs(t, d.Validate(), ErrMaxDepth)
+	d.ResetBytes([]byte(`[1]`))
+	require.Equal(t, l, d.Limits())
+	require.ErrorIs(t, d.Arr(nil), ErrMaxBytes)
+
+	PutDecoder(d)
+	require.Equal(t, Limits{}, d.Limits())
+}
@@ -0,0 +1,153 @@
+package jx
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/go-faster/errors"
+)
+
+// LineError describes failure of single line in newline-delimited json.
+type LineError struct {
+	Line int // 1-based
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// LineReader reads newline-delimited json (NDJSON, JSON Lines), where every
+// line is a separate json value.
+//
+// Blank lines are ignored. Malformed line does not affect next lines, so
+// decoding can continue after error.
+type LineReader struct {
+	r    *bufio.Reader
+	d    Decoder
+	buf  []byte // buffer for lines that do not fit into bufio.Reader
+	line []byte // current line without line terminator
+
+	n           int // current line number
+	skipInvalid bool
+	skipped     int
+	err         error
+}
+
+// NewLineReader creates new LineReader.
+func NewLineReader(r io.Reader) *LineReader {
+	return &LineReader{r: bufio.NewReader(r)}
+}
+
+// SetSkipInvalid sets whether Next should validate lines and skip ones
+// that are not valid json.
+func (r *LineReader) SetSkipInvalid(skip bool) {
+	r.skipInvalid = skip
+}
+
+// Skipped returns count of invalid lines skipped by Next.
+func (r *LineReader) Skipped() int {
+	return r.skipped
+}
+
+// Next reads next non-blank line, returning false on end of input or
+// read error.
+func (r *LineReader) Next() bool {
+	for r.err == nil {
+		line, err := r.readLine()
+		if err != nil {
+			if err != io.EOF {
+				r.err = errors.Wrapf(err, "read line %d", r.n+1)
+			}
+			return false
+		}
+		r.n++
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		r.line = line
+		r.d.ResetBytes(line)
+		if r.skipInvalid {
+			if err := r.d.Validate(); err != nil {
+				r.skipped++
+				continue
+			}
+			r.d.ResetBytes(line)
+		}
+		return true
+	}
+	return false
+}
+
+// readLine reads next line, trimming line terminator.
+func (r *LineReader) readLine() ([]byte, error) {
+	r.buf = r.buf[:0]
+	for {
+		line, err := r.r.ReadSlice('\n')
+		switch err {
+		case nil:
+		case bufio.ErrBufferFull:
+			// Line is longer than buffer, accumulate.
+			r.buf = append(r.buf, line...)
+			continue
+		case io.EOF:
+			if len(line) == 0 && len(r.buf) == 0 {
+				return nil, io.EOF
+			}
+		default:
+			return nil, err
+		}
+		if len(r.buf) > 0 {
+			line = append(r.buf, line...)
+			r.buf = line
+		}
+		line = bytes.TrimSuffix(line, []byte{'\n'})
+		line = bytes.TrimSuffix(line, []byte{'\r'})
+		return line, nil
+	}
+}
+
+// Line returns number of current line, starting from 1.
+func (r *LineReader) Line() int {
+	return r.n
+}
+
+// Raw returns current line.
+//
+// Value is valid only until next call to Next.
+func (r *LineReader) Raw() Raw {
+	return r.line
+}
+
+// Decoder returns Decoder for current line.
+//
+// Decoder is reused between lines, so its settings, like limits, are kept.
+func (r *LineReader) Decoder() *Decoder {
+	return &r.d
+}
+
+// Decode calls f with Decoder for current line, checking that f consumed
+// the whole line.
+//
+// Returned error is *LineError.
+func (r *LineReader) Decode(f func(d *Decoder) error) error {
+	r.d.ResetBytes(r.line)
+	if err := f(&r.d); err != nil {
+		return &LineError{Line: r.n, Err: err}
+	}
+	if err := r.d.Skip(); err != io.EOF {
+		return &LineError{Line: r.n, Err: r.d.decodeErr(errors.Wrap(err, "unexpected trialing data"))}
+	}
+	return nil
+}
+
+// Err returns read error, if any, that was encountered by Next.
+func (r *LineReader) Err() error {
+	return r.err
+}
@@ -0,0 +1,135 @@
+package jx
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/go-faster/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const testLines = `{"id":1,"msg":"first"}
+{"id":2,"msg":"second"}
+
+{"id":3,"msg":broken}
+  {"id":4, "msg":"fourth"}  ` + "\r" + `
+[5]
+`
+
+func TestLineReader(t *testing.T) {
+	type record struct {
+		Line int
+		ID   int
+	}
+	decode := func(d *Decoder) (id int, _ error) {
+		if d.Next() != Object {
+			return 0, errors.Errorf("unexpected %s", d.Next())
+		}
+		return id, d.Obj(func(d *Decoder, key string) error {
+			switch key {
+			case "id":
+				v, err := d.Int()
+				id = v
+				return err
+			default:
+				return d.Skip()
+			}
+		})
+	}
+
+	t.Run("Decode", func(t *testing.T) {
+		r := NewLineReader(strings.NewReader(testLines))
+		var (
+			records []record
+			errs    []*LineError
+		)
+		for r.Next() {
+			var id int
+			if err := r.Decode(func(d *Decoder) (err error) {
+				id, err = decode(d)
+				return err
+			}); err != nil {
+				var lineErr *LineError
+				require.ErrorAs(t, err, &lineErr)
+				errs = append(errs, lineErr)
+				continue
+			}
+			records = append(records, record{Line: r.Line(), ID: id})
+		}
+		require.NoError(t, r.Err())
+		require.Equal(t, []record{
+			{Line: 1, ID: 1},
+			{Line: 2, ID: 2},
+			{Line: 5, ID: 4},
+		}, records)
+		require.Len(t, errs, 2)
+		require.Equal(t, 4, errs[0].Line)
+		require.Equal(t, 6, errs[1].Line)
+
+		var decErr *DecodeError
+		require.ErrorAs(t, errs[0], &decErr)
+		require.Equal(t, 15, decErr.Column)
+		require.Contains(t, errs[0].Error(), "line 4: ")
+	})
+	t.Run("SkipInvalid", func(t *testing.T) {
+		r := NewLineReader(iotest.OneByteReader(strings.NewReader(testLines)))
+		r.SetSkipInvalid(true)
+		var lines []int
+		for r.Next() {
+			lines = append(lines, r.Line())
+			require.True(t, Valid(r.Raw()))
+		}
+		require.NoError(t, r.Err())
+		require.Equal(t, []int{1, 2, 5, 6}, lines)
+		require.Equal(t, 1, r.Skipped())
+	})
+	t.Run("TrailingData", func(t *testing.T) {
+		r := NewLineReader(strings.NewReader(`1 2`))
+		require.True(t, r.Next())
+		err := r.Decode(func(d *Decoder) error {
+			_, err := d.Int()
+			return err
+		})
+		var lineErr *LineError
+		require.ErrorAs(t, err, &lineErr)
+		require.Equal(t, 1, lineErr.Line)
+		require.False(t, r.Next())
+	})
+	t.Run("LongLine", func(t *testing.T) {
+		long := `"` + strings.Repeat("a", 10000) + `"`
+		r := NewLineReader(strings.NewReader(long + "\n" + long))
+		var n int
+		for r.Next() {
+			n++
+			s, err := r.Decoder().Str()
+			require.NoError(t, err)
+			require.Len(t, s, 10000)
+		}
+		require.NoError(t, r.Err())
+		require.Equal(t, 2, n)
+	})
+	t.Run("ReadError", func(t *testing.T) {
+		r := NewLineReader(io.MultiReader(
+			strings.NewReader("1\n"),
+			iotest.ErrReader(io.ErrClosedPipe),
+		))
+		require.True(t, r.Next())
+		require.False(t, r.Next())
+		require.ErrorIs(t, r.Err(), io.ErrClosedPipe)
+		require.False(t, r.Next())
+	})
+	t.Run("Limits", func(t *testing.T) {
+		r := NewLineReader(bytes.NewBufferString("[1,2]\n[1,2,3]\n"))
+		r.Decoder().SetLimits(Limits{MaxArrElems: 2})
+		r.SetSkipInvalid(true)
+		var n int
+		for r.Next() {
+			n++
+		}
+		require.Equal(t, 1, n)
+		require.Equal(t, 1, r.Skipped())
+	})
+}
@@ -7,7 +7,20 @@ import (
 // ObjBytes calls f for every key in object, using byte slice as key.
 //
 // The key value is valid only until f is not returned.
+//
+// Returned error is *DecodeError, describing location of failure.
 func (d *Decoder) ObjBytes(f func(d *Decoder, key []byte) error) error {
+	if err := d.objBytes(f, nil); err != nil {
+		return d.decodeErr(err)
+	}
+	return nil
+}
+
+// objBytes reads object like ObjBytes, setting *raw to raw key before each
+// callback if raw is not nil.
+//
+// Raw key references internal buffer and is valid until next read.
+func (d *Decoder) objBytes(f func(d *Decoder, key []byte) error, raw *[]byte) error {
 	if err := d.consume('{'); err != nil {
 		return errors.Wrap(err, `"{" expected`)
 	}
@@ -24,6 +37,7 @@ func (d *Decoder) ObjBytes(f func(d *Decoder, key []byte) error) error {
 	if c == '}' {
 		return d.decDepth()
 	}
+	frame, keys := d.pushPath(true), d.pushKeys()
 	d.unread()
 	// Do not reference internal buffer for key if decoder is not buffered.
 	//
@@ -32,18 +46,11 @@ func (d *Decoder) ObjBytes(f func(d *Decoder, key []byte) error) error {
 	// See https://github.com/go-faster/jx/pull/62.
 	isBuffer := d.reader == nil
 
-	k, err := d.str(value{raw: isBuffer})
+	k, err := d.objField(value{raw: isBuffer}, keys, raw)
 	if err != nil {
-		return errors.Wrap(err, "field name")
-	}
-	if err := d.consume(':'); err != nil {
-		return errors.Wrap(err, `":" expected`)
-	}
-	// Skip whitespace.
-	if _, err = d.more(); err != nil {
 		return err
 	}
-	d.unread()
+	d.path[frame].key = k.buf
 	if err := f(d, k.buf); err != nil {
 		return errors.Wrap(err, "callback")
 	}
@@ -52,19 +59,21 @@ func (d *Decoder) ObjBytes(f func(d *Decoder, key []byte) error) error {
 	if err != nil {
 		return errors.Wrap(err, `"," or "}" expected`)
 	}
-	for c == ',' {
-		k, err := d.str(value{raw: isBuffer})
-		if err != nil {
-			return errors.Wrap(err, "field name")
+	for n := 2; c == ','; n++ {
+		if end, err := d.trailingComma('}'); err != nil {
+			return err
+		} else if end {
+			c = '}'
+			break
 		}
-		if err := d.consume(':'); err != nil {
-			return errors.Wrap(err, `":" expected`)
+		if err := d.checkObjKeys(n); err != nil {
+			return err
 		}
-		// Check that value exists.
-		if _, err = d.more(); err != nil {
+		k, err := d.objField(value{raw: isBuffer}, keys, raw)
+		if err != nil {
 			return err
 		}
-		d.unread()
+		d.path[frame].key = k.buf
 		if err := f(d, k.buf); err != nil {
 			return errors.Wrap(err, "callback")
 		}
@@ -76,9 +85,70 @@ func (d *Decoder) ObjBytes(f func(d *Decoder, key []byte) error) error {
 		err := badToken(c, d.offset()-1)
 		return errors.Wrap(err, `"}" expected`)
 	}
+	d.popPath(frame)
+	d.popKeys(keys)
 	return d.decDepth()
 }
 
+// objField reads object key and colon, skipping whitespace before value.
+//
+// If raw is not nil, it is set to raw key. Buffer is pinned while key is
+// read, so raw key is not overwritten by reader.
+func (d *Decoder) objField(v value, keys int, raw *[]byte) (value, error) {
+	pins := len(d.pins)
+	if raw != nil {
+		if _, err := d.more(); err != nil {
+			return value{}, errors.Wrap(err, "field name")
+		}
+		d.unread()
+		d.pins = append(d.pins, pin{offset: d.offset()})
+		defer func() {
+			d.pins = d.pins[:pins]
+		}()
+	}
+	k, err := d.objKey(v, keys)
+	if err != nil {
+		return k, errors.Wrap(err, "field name")
+	}
+	end := d.offset()
+	if err := d.consume(':'); err != nil {
+		return k, errors.Wrap(err, `":" expected`)
+	}
+	// Check that value exists.
+	if _, err := d.more(); err != nil {
+		return k, err
+	}
+	d.unread()
+	if raw != nil {
+		// Key is kept in buffer by pin.
+		*raw = d.buf[d.pins[pins].offset-d.streamOffset : end-d.streamOffset]
+	}
+	return k, nil
+}
+
+// objKey reads object key, checking it for duplicates in key set with given
+// index if it is not negative.
+func (d *Decoder) objKey(v value, keys int) (value, error) {
+	if keys < 0 {
+		return d.key(v)
+	}
+	// Skip whitespace to get offset of key.
+	if _, err := d.more(); err != nil {
+		return value{}, err
+	}
+	d.unread()
+	offset := d.offset()
+
+	k, err := d.key(v)
+	if err != nil {
+		return k, err
+	}
+	if !d.keys[keys].add(k.buf) {
+		return value{}, &DuplicateKeyError{Key: string(k.buf), Offset: offset}
+	}
+	return k, nil
+}
+
 // Obj reads json object, calling f on each field.
 //
 // Use ObjBytes to reduce heap allocations for keys.
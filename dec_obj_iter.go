@@ -7,6 +7,9 @@ type ObjIter struct {
 	d        *Decoder
 	key      []byte
 	err      error
+	frame    int
+	keySet   int
+	keys     int
 	isBuffer bool
 	closed   bool
 	comma    bool
@@ -15,16 +18,21 @@ type ObjIter struct {
 // ObjIter creates new object iterator.
 func (d *Decoder) ObjIter() (ObjIter, error) {
 	if err := d.consume('{'); err != nil {
-		return ObjIter{}, errors.Wrap(err, `"{" expected`)
+		return ObjIter{}, d.decodeErr(errors.Wrap(err, `"{" expected`))
 	}
 	if err := d.incDepth(); err != nil {
-		return ObjIter{}, err
+		return ObjIter{}, d.decodeErr(err)
 	}
 	if _, err := d.more(); err != nil {
-		return ObjIter{}, err
+		return ObjIter{}, d.decodeErr(err)
 	}
 	d.unread()
-	return ObjIter{d: d, isBuffer: d.reader == nil}, nil
+	return ObjIter{
+		d:        d,
+		frame:    d.pushPath(true),
+		keySet:   d.pushKeys(),
+		isBuffer: d.reader == nil,
+	}, nil
 }
 
 // Key returns current key.
@@ -39,52 +47,71 @@ func (i *ObjIter) Next() bool {
 	if i.closed || i.err != nil {
 		return false
 	}
+	if err := i.next(); err != nil {
+		i.err = i.d.decodeErr(err)
+		return false
+	}
+	return !i.closed
+}
 
+func (i *ObjIter) next() error {
 	dec := i.d
 	c, err := dec.more()
 	if err != nil {
-		i.err = err
-		return false
+		return err
 	}
 	if c == '}' {
-		i.closed = true
-		i.err = dec.decDepth()
-		return false
+		return i.close()
 	}
 	if i.comma {
 		if c != ',' {
 			err := badToken(c, dec.offset()-1)
-			i.err = errors.Wrap(err, `"," expected`)
-			return false
+			return errors.Wrap(err, `"," expected`)
+		}
+		if end, err := dec.trailingComma('}'); err != nil {
+			return err
+		} else if end {
+			return i.close()
 		}
 	} else {
 		dec.unread()
 	}
 
-	k, err := dec.str(value{raw: i.isBuffer})
+	i.keys++
+	if err := dec.checkObjKeys(i.keys); err != nil {
+		return err
+	}
+	k, err := dec.objKey(value{raw: i.isBuffer}, i.keySet)
 	if err != nil {
-		i.err = errors.Wrap(err, "field name")
-		return false
+		return errors.Wrap(err, "field name")
 	}
+	dec.path[i.frame].key = k.buf
 	if err := dec.consume(':'); err != nil {
-		i.err = errors.Wrap(err, `":" expected`)
-		return false
+		return errors.Wrap(err, `":" expected`)
 	}
 	// Skip whitespace.
 	if _, err = dec.more(); err != nil {
 		err := badToken(c, dec.offset()-1)
-		i.err = errors.Wrap(err, `"," or "}" expected`)
-		return false
+		return errors.Wrap(err, `"," or "}" expected`)
 	}
 	dec.unread()
 
 	i.comma = true
 	i.key = k.buf
 
-	return true
+	return nil
+}
+
+func (i *ObjIter) close() error {
+	i.closed = true
+	i.d.popPath(i.frame)
+	i.d.popKeys(i.keySet)
+	return i.d.decDepth()
 }
 
 // Err returns the error, if any, that was encountered during iteration.
+//
+// Error is *DecodeError, describing location of failure.
 func (i *ObjIter) Err() error {
 	return i.err
 }
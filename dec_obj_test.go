@@ -42,7 +42,7 @@ func TestDecoder_ObjBytes(t *testing.T) {
 		d := DecodeBytes(input)
 		require.ErrorIs(t, d.ObjBytes(func(d *Decoder, key []byte) error {
 			return crawlValue(d)
-		}), errMaxDepth)
+		}), ErrMaxDepth)
 	})
 	t.Run("Invalid", func(t *testing.T) {
 		for _, s := range testObjs {
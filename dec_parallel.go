@@ -0,0 +1,142 @@
+package jx
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-faster/errors"
+)
+
+// SplitArr reads array and appends its elements to elems.
+//
+// Decoder must read byte slice, elements reference it. Objects and arrays are
+// skipped with structural index if possible, see Skip.
+func (d *Decoder) SplitArr(elems []Raw) ([]Raw, error) {
+	return d.splitArr(elems, nil)
+}
+
+// splitArr is SplitArr that also appends offsets of elements to offsets,
+// if it is not nil.
+func (d *Decoder) splitArr(elems []Raw, offsets *[]int) ([]Raw, error) {
+	if d.reader != nil {
+		return elems, errors.New("split: input is not byte slice")
+	}
+	err := d.Arr(func(d *Decoder) error {
+		offset := d.offset()
+		raw, err := d.Raw()
+		if err != nil {
+			return err
+		}
+		elems = append(elems, raw)
+		if offsets != nil {
+			*offsets = append(*offsets, offset)
+		}
+		return nil
+	})
+	return elems, err
+}
+
+// ParallelArr reads array from d and calls f for each element concurrently,
+// returning results in order of elements.
+//
+// Array is split by SplitArr, so d must read byte slice. Elements are
+// processed by workers goroutines, or runtime.GOMAXPROCS(0) if workers is
+// not positive. Each worker has its own Decoder, that inherits settings of d
+// and is reset to element before f call.
+//
+// On first error, context passed to f is canceled, remaining elements are
+// not processed and error is returned. Error of f is wrapped with index of
+// element. Offset, line, column and path of *DecodeError returned by f are
+// relative to element, they are converted to be relative to input of d.
+func ParallelArr[T any](
+	ctx context.Context,
+	d *Decoder,
+	workers int,
+	f func(ctx context.Context, d *Decoder) (T, error),
+) ([]T, error) {
+	var offsets []int
+	elems, err := d.splitArr(nil, &offsets)
+	if err != nil {
+		return nil, errors.Wrap(err, "split")
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(elems) {
+		workers = len(elems)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		results = make([]T, len(elems))
+		next    int64
+		wg      sync.WaitGroup
+
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+	// Elements are nested in array.
+	depth := d.depth + 1
+	sub := d.sub(nil)
+	path := d.pathTokens()
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wd := sub
+			for {
+				i := int(atomic.AddInt64(&next, 1) - 1)
+				if i >= len(elems) {
+					return
+				}
+				if err := ctx.Err(); err != nil {
+					fail(err)
+					return
+				}
+				wd.ResetBytes(elems[i])
+				wd.depth = depth
+				v, err := f(ctx, &wd)
+				if err != nil {
+					d.elemErr(err, path, i, offsets[i])
+					fail(errors.Wrapf(err, "element %d", i))
+					return
+				}
+				results[i] = v
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
+// elemErr converts position of *DecodeError in error of i-th element, that
+// starts at offset, to be relative to input of d. Path of array is prefix.
+//
+// Safe for concurrent use, d is not changed.
+func (d *Decoder) elemErr(err error, prefix []string, i, offset int) {
+	e, ok := errors.Into[*DecodeError](err)
+	if !ok {
+		return
+	}
+	e.Offset += offset
+	e.Line, e.Column = d.position(e.Offset)
+	path := make([]string, 0, len(prefix)+1+len(e.Path))
+	path = append(path, prefix...)
+	path = append(path, strconv.Itoa(i))
+	e.Path = append(path, e.Path...)
+}
@@ -0,0 +1,158 @@
+package jx
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-faster/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_SplitArr(t *testing.T) {
+	t.Run("Elements", func(t *testing.T) {
+		elems, err := DecodeStr(`[ 1, "a" ,{"b": [2, 3]} ,[], null ]`).SplitArr(nil)
+		require.NoError(t, err)
+		require.Equal(t, []Raw{
+			Raw(`1`),
+			Raw(`"a"`),
+			Raw(`{"b": [2, 3]}`),
+			Raw(`[]`),
+			Raw(`null`),
+		}, elems)
+	})
+	t.Run("Empty", func(t *testing.T) {
+		elems, err := DecodeStr(`[]`).SplitArr(nil)
+		require.NoError(t, err)
+		require.Empty(t, elems)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		for _, input := range []string{
+			``,
+			`{}`,
+			`[1,]`,
+			`[{"a":1}, {"b"}]`,
+		} {
+			_, err := DecodeStr(input).SplitArr(nil)
+			require.Error(t, err, input)
+		}
+	})
+	t.Run("Reader", func(t *testing.T) {
+		_, err := Decode(strings.NewReader(`[1]`), 0).SplitArr(nil)
+		require.Error(t, err)
+	})
+}
+
+func TestParallelArr(t *testing.T) {
+	const n = 1000
+	var e Encoder
+	e.Arr(func(e *Encoder) {
+		for i := 0; i < n; i++ {
+			e.Obj(func(e *Encoder) {
+				e.Field("id", func(e *Encoder) {
+					e.Int(i)
+				})
+				e.Field("name", func(e *Encoder) {
+					e.Str(strconv.Itoa(i))
+				})
+			})
+		}
+	})
+	decodeID := func(ctx context.Context, d *Decoder) (id int, err error) {
+		err = d.Obj(func(d *Decoder, key string) error {
+			switch key {
+			case "id":
+				id, err = d.Int()
+				return err
+			default:
+				return d.Skip()
+			}
+		})
+		return id, err
+	}
+
+	t.Run("Ordered", func(t *testing.T) {
+		for _, workers := range []int{0, 1, 3, 2 * n} {
+			ids, err := ParallelArr(context.Background(), DecodeBytes(e.Bytes()), workers, decodeID)
+			require.NoError(t, err)
+			require.Len(t, ids, n)
+			for i, id := range ids {
+				require.Equal(t, i, id)
+			}
+		}
+	})
+	t.Run("Empty", func(t *testing.T) {
+		ids, err := ParallelArr(context.Background(), DecodeStr(`[]`), 0, decodeID)
+		require.NoError(t, err)
+		require.Empty(t, ids)
+	})
+	t.Run("Settings", func(t *testing.T) {
+		d := DecodeBytes(e.Bytes())
+		d.SetLimits(Limits{MaxDepth: 10})
+		_, err := ParallelArr(context.Background(), d, 2, func(ctx context.Context, d *Decoder) (int, error) {
+			require.Equal(t, 10, d.Limits().MaxDepth)
+			require.Equal(t, 1, d.depth)
+			return 0, d.Skip()
+		})
+		require.NoError(t, err)
+	})
+	t.Run("Error", func(t *testing.T) {
+		testErr := errors.New("test")
+		var calls int64
+		_, err := ParallelArr(context.Background(), DecodeBytes(e.Bytes()), 4, func(ctx context.Context, d *Decoder) (int, error) {
+			atomic.AddInt64(&calls, 1)
+			id, err := decodeID(ctx, d)
+			if err != nil {
+				return 0, err
+			}
+			if id == 10 {
+				return 0, testErr
+			}
+			return id, nil
+		})
+		require.ErrorIs(t, err, testErr)
+		require.EqualError(t, err, "element 10: test")
+		require.Less(t, atomic.LoadInt64(&calls), int64(n))
+	})
+	t.Run("DecodeError", func(t *testing.T) {
+		const input = "{\"x\": [\n  {\"id\": 1},\n  {\"id\": \"2\"}\n]}"
+		err := DecodeStr(input).Obj(func(d *Decoder, key string) error {
+			_, err := ParallelArr(context.Background(), d, 2, decodeID)
+			return err
+		})
+		e, ok := errors.Into[*DecodeError](err)
+		require.True(t, ok, "%+v", err)
+		require.Equal(t, "/x/1/id", e.Pointer())
+		require.Equal(t, strings.Index(input, `"2"`), e.Offset)
+		require.Equal(t, 3, e.Line)
+		require.Equal(t, 10, e.Column)
+	})
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := ParallelArr(ctx, DecodeBytes(e.Bytes()), 4, decodeID)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := ParallelArr(context.Background(), DecodeStr(`[1,2`), 4, decodeID)
+		require.Error(t, err)
+	})
+}
+
+func BenchmarkDecoder_SplitArr(b *testing.B) {
+	data := benchData
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+
+	var elems []Raw
+	d := DecodeBytes(data)
+	for i := 0; i < b.N; i++ {
+		d.ResetBytes(data)
+		var err error
+		if elems, err = d.SplitArr(elems[:0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
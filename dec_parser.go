@@ -0,0 +1,276 @@
+package jx
+
+import (
+	"io"
+
+	"github.com/go-faster/errors"
+)
+
+// ErrNeedMore means that Parser needs more input to read next token.
+var ErrNeedMore = errors.New("need more data")
+
+// Parser is non-blocking push parser, that reads json tokens from chunks of
+// input, like frames of network protocol.
+//
+// Input is added by Feed, then tokens are read by Token until it returns
+// ErrNeedMore:
+//
+//	p.Feed(chunk)
+//	for {
+//		t, err := p.Token()
+//		if errors.Is(err, jx.ErrNeedMore) {
+//			break // Wait for next chunk.
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		handle(t)
+//	}
+//
+// Tokens are read with the same grammar and settings as Decoder.Token.
+// Incomplete token is read again only when fed input can complete it, so
+// token split into many chunks is read in linear time.
+type Parser struct {
+	d  *Decoder
+	in parserInput
+
+	// wait is true if last token was incomplete, then scan is state of
+	// pending input, pending is its length and scanned is length of fed
+	// input that is scanned.
+	wait    bool
+	scan    parserScan
+	pending int
+	scanned int
+	// retry is length of pending input to read token again even if scan
+	// does not see its end, so errors inside of token are not delayed
+	// indefinitely.
+	retry int
+}
+
+// parserScan is lexical state of pending input, used to detect whether fed
+// input can complete token without reading it again.
+type parserScan struct {
+	state   byte
+	quote   byte
+	escaped bool
+	word    int // length of current word
+}
+
+// States of parserScan.
+const (
+	scanSpace byte = iota
+	scanStr
+	scanWord
+	scanSlash
+	scanLineComment
+	scanBlockComment
+	scanBlockStar
+)
+
+// scanShortWord is max length of word that is read again on each byte,
+// because literals like true or Infinity are complete without delimiter.
+const scanShortWord = len("-Infinity")
+
+// feed scans input and reports whether it can complete token.
+func (s *parserScan) feed(input []byte, relaxed bool) (end bool) {
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch s.state {
+		case scanStr:
+			switch {
+			case s.escaped:
+				s.escaped = false
+			case c == '\\':
+				s.escaped = true
+			case c == s.quote:
+				s.state = scanSpace
+				end = true
+			}
+			continue
+		case scanWord:
+			if !scanDelim(c, relaxed) {
+				s.word++
+				end = end || s.word <= scanShortWord
+				continue
+			}
+			// Delimiter completes word and is scanned as next byte.
+			s.state = scanSpace
+			end = true
+		case scanSlash:
+			switch c {
+			case '/':
+				s.state = scanLineComment
+				continue
+			case '*':
+				s.state = scanBlockComment
+				continue
+			}
+			// Not a comment, token can not be complete.
+			s.state = scanSpace
+			end = true
+		case scanLineComment:
+			if c == '\n' {
+				s.state = scanSpace
+			}
+			continue
+		case scanBlockComment, scanBlockStar:
+			switch {
+			case c == '*':
+				s.state = scanBlockStar
+			case c == '/' && s.state == scanBlockStar:
+				s.state = scanSpace
+			default:
+				s.state = scanBlockComment
+			}
+			continue
+		}
+		switch {
+		case spaceSet[c] == 1:
+		case c == '"' || (relaxed && c == '\''):
+			s.state, s.quote, s.escaped = scanStr, c, false
+		case relaxed && c == '/':
+			s.state = scanSlash
+		case scanDelim(c, relaxed):
+			// Structural or invalid byte.
+			end = true
+		default:
+			s.state, s.word = scanWord, 1
+			end = true
+		}
+	}
+	return end
+}
+
+// scanDelim reports whether c ends word.
+func scanDelim(c byte, relaxed bool) bool {
+	switch c {
+	case '{', '}', '[', ']', ',', ':', '"', ' ', '\n', '\t', '\r':
+		return true
+	case '\'', '/':
+		return relaxed
+	}
+	return false
+}
+
+// parserInput is reader of fed input.
+type parserInput struct {
+	buf    []byte
+	off    int
+	closed bool
+}
+
+func (r *parserInput) Read(p []byte) (int, error) {
+	if r.off == len(r.buf) {
+		if r.closed {
+			return 0, io.EOF
+		}
+		return 0, ErrNeedMore
+	}
+	n := copy(p, r.buf[r.off:])
+	r.off += n
+	if r.off == len(r.buf) {
+		r.buf, r.off = r.buf[:0], 0
+	}
+	return n, nil
+}
+
+// NewParser creates new Parser with given buffer size.
+//
+// Buffer grows if token does not fit into it.
+func NewParser(bufSize int) *Parser {
+	p := &Parser{}
+	p.d = Decode(&p.in, bufSize)
+	return p
+}
+
+// Feed adds chunk of input. Chunk is copied and can be reused.
+//
+// Feed must not be called after Close.
+func (p *Parser) Feed(chunk []byte) {
+	p.in.buf = append(p.in.buf, chunk...)
+}
+
+// Close marks end of input, so Token returns io.EOF after last top-level
+// value instead of ErrNeedMore, and reports incomplete value as error.
+func (p *Parser) Close() {
+	p.in.closed = true
+}
+
+// Reset resets parser to read new input, keeping settings.
+func (p *Parser) Reset() {
+	p.in = parserInput{buf: p.in.buf[:0]}
+	p.wait = false
+	p.d.Reset(&p.in)
+}
+
+// SetLimits sets decoding limits, see Decoder.SetLimits.
+func (p *Parser) SetLimits(l Limits) {
+	p.d.SetLimits(l)
+}
+
+// SetRelaxed sets relaxed mode, see Decoder.SetRelaxed.
+func (p *Parser) SetRelaxed(v bool) {
+	p.d.SetRelaxed(v)
+}
+
+// SetUTF8Mode sets UTF-8 mode, see Decoder.SetUTF8Mode.
+func (p *Parser) SetUTF8Mode(m UTF8Mode) {
+	p.d.SetUTF8Mode(m)
+}
+
+// SetDisallowDuplicateKeys sets duplicate keys check, see
+// Decoder.SetDisallowDuplicateKeys.
+func (p *Parser) SetDisallowDuplicateKeys(v bool) {
+	p.d.SetDisallowDuplicateKeys(v)
+}
+
+// Token reads next json token.
+//
+// Returns ErrNeedMore if input ends before token is complete, io.EOF if
+// input is closed and there are no more top-level values. Other errors are
+// *DecodeError, describing location of failure.
+//
+// Raw of returned token is valid only until next call.
+func (p *Parser) Token() (Token, error) {
+	d := p.d
+	if p.wait && !p.in.closed {
+		// Input is not read by decoder while waiting.
+		fed := p.in.buf[p.scanned:]
+		p.scanned = len(p.in.buf)
+		p.pending += len(fed)
+		if !p.scan.feed(fed, d.relaxed) && p.pending < p.retry {
+			// Token can not be complete, do not read it again.
+			return Token{}, ErrNeedMore
+		}
+	}
+	c := d.Checkpoint()
+	t, err := d.token()
+	if err != nil && errors.Is(err, ErrNeedMore) {
+		// Read token again when more input is fed.
+		if err := d.Rewind(c); err != nil {
+			return Token{}, err
+		}
+		d.Commit(c)
+		if !p.wait {
+			// Pending input is scanned once, then only fed input.
+			pending := d.buf[d.head:d.tail]
+			p.wait = true
+			p.scan = parserScan{}
+			p.scan.feed(pending, d.relaxed)
+			p.pending = len(pending)
+		}
+		// All fed input is read.
+		p.scanned = 0
+		p.retry = 2 * p.pending
+		return Token{}, ErrNeedMore
+	}
+	d.Commit(c)
+	p.wait = false
+	if err != nil {
+		if err == io.EOF && len(d.tokens) == 0 {
+			return Token{}, io.EOF
+		}
+		return Token{}, d.decodeErr(err)
+	}
+	return t, nil
+}
@@ -0,0 +1,237 @@
+package jx
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-faster/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// parserTokens feeds input to p by chunks of given size and returns tokens.
+func parserTokens(p *Parser, input string, chunk int) ([]string, error) {
+	var r []string
+	for {
+		t, err := p.Token()
+		switch {
+		case errors.Is(err, ErrNeedMore):
+			if input == "" {
+				p.Close()
+				continue
+			}
+			n := chunk
+			if n > len(input) {
+				n = len(input)
+			}
+			p.Feed([]byte(input[:n]))
+			input = input[n:]
+			continue
+		case err == io.EOF:
+			return r, nil
+		case err != nil:
+			return r, err
+		}
+		r = append(r, fmt.Sprintf("%s@%d:%s", t.Kind, t.Depth, t.Raw))
+	}
+}
+
+func TestParser(t *testing.T) {
+	test := func(t *testing.T, input string) {
+		t.Helper()
+		expected, expectedErr := tokenString(DecodeStr(input))
+		for _, chunk := range []int{1, 2, 3, 7, 64, len(input) + 1} {
+			tokens, err := parserTokens(NewParser(4), input, chunk)
+			require.Equal(t, expected, tokens, "chunk %d", chunk)
+			if expectedErr != nil {
+				require.EqualError(t, err, expectedErr.Error(), "chunk %d", chunk)
+			} else {
+				require.NoError(t, err, "chunk %d", chunk)
+			}
+		}
+	}
+	for _, input := range []string{
+		``,
+		`{"a": [1, "x", {"b": null}], "c": {}, "d": [], "e": true} 10`,
+		`1 22 333 "4444" true false null`,
+		`[-1.5e+10, 0, "é\n", ""]`,
+		`{"a":{"a":1},"a":2}`,
+		`{"a"}`,
+		`[1 2]`,
+		`[1,2`,
+		`[[1],{"b":[x]}]`,
+		`"unterminated`,
+		`[tru]`,
+		`[1.]`,
+	} {
+		t.Run(input, func(t *testing.T) {
+			test(t, input)
+		})
+	}
+	t.Run("Testdata", func(t *testing.T) {
+		runTestdata(t.Fatal, func(name string, data []byte) {
+			if len(data) > 4096 {
+				return
+			}
+			test(t, string(data))
+		})
+	})
+}
+
+func TestParser_NeedMore(t *testing.T) {
+	p := NewParser(0)
+	_, err := p.Token()
+	require.ErrorIs(t, err, ErrNeedMore)
+
+	// Number may continue in next chunk.
+	p.Feed([]byte(`[12`))
+	tok, err := p.Token()
+	require.NoError(t, err)
+	require.Equal(t, TokenArrStart, tok.Kind)
+	_, err = p.Token()
+	require.ErrorIs(t, err, ErrNeedMore)
+
+	p.Feed([]byte(`3, "fo`))
+	tok, err = p.Token()
+	require.NoError(t, err)
+	require.Equal(t, Token{Kind: TokenNumber, Raw: Raw(`123`), Depth: 1}, tok)
+	_, err = p.Token()
+	require.ErrorIs(t, err, ErrNeedMore)
+
+	p.Feed([]byte(`o"]`))
+	tok, err = p.Token()
+	require.NoError(t, err)
+	require.Equal(t, Token{Kind: TokenStr, Raw: Raw(`"foo"`), Depth: 1}, tok)
+	tok, err = p.Token()
+	require.NoError(t, err)
+	require.Equal(t, TokenArrEnd, tok.Kind)
+
+	// Top-level number is complete only on Close.
+	p.Feed([]byte(` 1`))
+	_, err = p.Token()
+	require.ErrorIs(t, err, ErrNeedMore)
+	p.Close()
+	tok, err = p.Token()
+	require.NoError(t, err)
+	require.Equal(t, Token{Kind: TokenNumber, Raw: Raw(`1`)}, tok)
+	_, err = p.Token()
+	require.ErrorIs(t, err, io.EOF)
+
+	t.Run("Reset", func(t *testing.T) {
+		p.Reset()
+		_, err := p.Token()
+		require.ErrorIs(t, err, ErrNeedMore)
+		p.Feed([]byte(`null `))
+		tok, err := p.Token()
+		require.NoError(t, err)
+		require.Equal(t, TokenNull, tok.Kind)
+	})
+}
+
+func TestParser_Settings(t *testing.T) {
+	t.Run("DuplicateKeys", func(t *testing.T) {
+		p := NewParser(0)
+		p.SetDisallowDuplicateKeys(true)
+		_, err := parserTokens(p, `{"a":{"a":1},"a":2}`, 3)
+		var dupErr *DuplicateKeyError
+		require.ErrorAs(t, err, &dupErr)
+		require.Equal(t, 13, dupErr.Offset)
+	})
+	t.Run("Limits", func(t *testing.T) {
+		p := NewParser(0)
+		p.SetLimits(Limits{MaxDepth: 2})
+		_, err := parserTokens(p, `[[[1]]]`, 1)
+		require.ErrorIs(t, err, ErrMaxDepth)
+	})
+	t.Run("Relaxed", func(t *testing.T) {
+		p := NewParser(0)
+		p.SetRelaxed(true)
+		tokens, err := parserTokens(p, `{a: 'b', /* c */ d: [1,],}`, 2)
+		require.NoError(t, err)
+		require.Len(t, tokens, 8)
+	})
+	t.Run("UTF8", func(t *testing.T) {
+		p := NewParser(0)
+		p.SetUTF8Mode(UTF8Strict)
+		_, err := parserTokens(p, "[\"\xff\"]", 1)
+		require.Error(t, err)
+	})
+}
+
+func TestParser_Large(t *testing.T) {
+	// Incomplete token is not read again on each chunk, otherwise time
+	// is quadratic.
+	t.Run("String", func(t *testing.T) {
+		s := `"` + strings.Repeat("a\\\"", 1<<18) + `"`
+		tokens, err := parserTokens(NewParser(0), s, 1024)
+		require.NoError(t, err)
+		require.Equal(t, []string{"string@0:" + s}, tokens)
+	})
+	t.Run("Keys", func(t *testing.T) {
+		const n = 100_000
+		var e Encoder
+		e.Obj(func(e *Encoder) {
+			for i := 0; i < n; i++ {
+				e.Field("key"+strconv.Itoa(i), func(e *Encoder) {
+					e.Int(i)
+				})
+			}
+		})
+		p := NewParser(0)
+		p.SetDisallowDuplicateKeys(true)
+		tokens, err := parserTokens(p, e.String(), 4096)
+		require.NoError(t, err)
+		require.Len(t, tokens, 2*n+2)
+	})
+	t.Run("Relaxed", func(t *testing.T) {
+		const input = `{'a\'b': "c\"/*", /* d */ e: [Infinity, 'f'], // g` + "\n" + `h: true}`
+		d := DecodeStr(input)
+		d.SetRelaxed(true)
+		expected, err := tokenString(d)
+		require.NoError(t, err)
+		for _, chunk := range []int{1, 2, 3, 5} {
+			p := NewParser(0)
+			p.SetRelaxed(true)
+			tokens, err := parserTokens(p, input, chunk)
+			require.NoError(t, err, "chunk %d", chunk)
+			require.Equal(t, expected, tokens, "chunk %d", chunk)
+		}
+	})
+}
+
+func BenchmarkParser_Token(b *testing.B) {
+	const chunk = 1024
+	data := benchData
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+
+	p := NewParser(0)
+	for i := 0; i < b.N; i++ {
+		p.Reset()
+		input := data
+		for {
+			_, err := p.Token()
+			if errors.Is(err, ErrNeedMore) {
+				if len(input) == 0 {
+					p.Close()
+					continue
+				}
+				n := chunk
+				if n > len(input) {
+					n = len(input)
+				}
+				p.Feed(input[:n])
+				input = input[n:]
+				continue
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package jx
+
+import (
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+var (
+	// ErrPointerNotFound means that value referenced by JSON Pointer does not exist.
+	ErrPointerNotFound = errors.New("pointer: not found")
+	// ErrInvalidPointer means that JSON Pointer is malformed.
+	ErrInvalidPointer = errors.New("pointer: invalid")
+)
+
+// nextPointerToken returns first unescaped reference token of non-empty
+// JSON Pointer and the rest of pointer.
+func nextPointerToken(ptr string) (tok, rest string, err error) {
+	if ptr[0] != '/' {
+		return "", "", errors.Wrapf(ErrInvalidPointer, "%q: must start with %q", ptr, "/")
+	}
+	ptr = ptr[1:]
+	tok, rest = ptr, ""
+	if idx := strings.IndexByte(ptr, '/'); idx >= 0 {
+		tok, rest = ptr[:idx], ptr[idx:]
+	}
+	if strings.IndexByte(tok, '~') < 0 {
+		return tok, rest, nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(tok))
+	for i := 0; i < len(tok); i++ {
+		c := tok[i]
+		if c != '~' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(tok) {
+			return "", "", errors.Wrapf(ErrInvalidPointer, "%q: incomplete escape", tok)
+		}
+		switch tok[i] {
+		case '0':
+			b.WriteByte('~')
+		case '1':
+			b.WriteByte('/')
+		default:
+			return "", "", errors.Wrapf(ErrInvalidPointer, "%q: bad escape %q", tok, tok[i-1:i+1])
+		}
+	}
+	return b.String(), rest, nil
+}
+
+// parsePointer splits JSON Pointer to unescaped reference tokens.
+func parsePointer(ptr string) (tokens []string, _ error) {
+	for ptr != "" {
+		tok, rest, err := nextPointerToken(ptr)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		ptr = rest
+	}
+	return tokens, nil
+}
+
+// parsePointerIndex parses array index reference token.
+//
+// Returns -1 if token is not a valid index.
+func parsePointerIndex(tok string) int {
+	if tok == "" || len(tok) > 1 && tok[0] == '0' {
+		return -1
+	}
+	n := 0
+	for i := 0; i < len(tok); i++ {
+		c := tok[i]
+		if c < '0' || c > '9' {
+			return -1
+		}
+		n = n*10 + int(c-'0')
+		if n < 0 {
+			// Overflow.
+			return -1
+		}
+	}
+	return n
+}
+
+// appendPointerToken escapes reference token and appends it to JSON Pointer.
+func appendPointerToken(ptr []byte, tok string) []byte {
+	ptr = append(ptr, '/')
+	for i := 0; i < len(tok); i++ {
+		switch c := tok[i]; c {
+		case '~':
+			ptr = append(ptr, '~', '0')
+		case '/':
+			ptr = append(ptr, '~', '1')
+		default:
+			ptr = append(ptr, c)
+		}
+	}
+	return ptr
+}
+
+// Pointer positions decoder at the value referenced by RFC 6901 JSON Pointer,
+// like "/resourceSpans/0/resource".
+//
+// Values before the target are skipped without decoding. On success, next
+// read call decodes referenced value. Returns error that wraps
+// ErrPointerNotFound if there is no such value and ErrInvalidPointer if
+// pointer is malformed.
+//
+// Objects and arrays containing target are not read to the end, so on
+// success decoder state is reset to the state before call: target is read
+// as a value at the same depth, and paths of errors are relative to it.
+//
+// Empty pointer references the whole document.
+func (d *Decoder) Pointer(ptr string) error {
+	depth, path, keys := d.depth, len(d.path), len(d.keys)
+	for ptr != "" {
+		tok, rest, err := nextPointerToken(ptr)
+		if err != nil {
+			return err
+		}
+		switch tt := d.Next(); tt {
+		case Object:
+			if err := d.pointerField(tok); err != nil {
+				return err
+			}
+		case Array:
+			if err := d.pointerElem(tok); err != nil {
+				return err
+			}
+		case Invalid:
+			// Report unexpected EOF or bad token.
+			if _, err := d.more(); err != nil {
+				return err
+			}
+			d.unread()
+			return d.Skip()
+		default:
+			return errors.Wrapf(ErrPointerNotFound, "token %q: %s has no children", tok, tt)
+		}
+		ptr = rest
+	}
+	d.depth = depth
+	d.popPath(path)
+	d.keys = d.keys[:keys]
+	return nil
+}
+
+func (d *Decoder) pointerField(key string) error {
+	iter, err := d.ObjIter()
+	if err != nil {
+		return err
+	}
+	for iter.Next() {
+		if string(iter.Key()) == key {
+			return nil
+		}
+		if err := d.Skip(); err != nil {
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return errors.Wrapf(ErrPointerNotFound, "key %q", key)
+}
+
+func (d *Decoder) pointerElem(tok string) error {
+	idx := parsePointerIndex(tok)
+	if idx < 0 {
+		if err := d.Skip(); err != nil {
+			return err
+		}
+		return errors.Wrapf(ErrPointerNotFound, "bad index %q", tok)
+	}
+	iter, err := d.ArrIter()
+	if err != nil {
+		return err
+	}
+	for i := 0; iter.Next(); i++ {
+		if i == idx {
+			// Skip whitespace after comma.
+			return d.skipSpace()
+		}
+		if err := d.Skip(); err != nil {
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return errors.Wrapf(ErrPointerNotFound, "index %d", idx)
+}
@@ -0,0 +1,183 @@
+package jx
+
+import "github.com/go-faster/errors"
+
+// PointerSet is a set of JSON Pointers compiled to a trie, used to extract
+// multiple values in a single pass over the input.
+//
+// Zero value is valid.
+type PointerSet struct {
+	root pointerNode
+	n    int
+}
+
+type pointerNode struct {
+	fields map[string]*pointerNode
+	elems  map[int]*pointerNode
+	// targets are indexes of pointers referencing this node.
+	targets []int
+}
+
+func (n *pointerNode) child(tok string) *pointerNode {
+	if c, ok := n.fields[tok]; ok {
+		return c
+	}
+	c := &pointerNode{}
+	if n.fields == nil {
+		n.fields = map[string]*pointerNode{}
+	}
+	n.fields[tok] = c
+	if idx := parsePointerIndex(tok); idx >= 0 {
+		if n.elems == nil {
+			n.elems = map[int]*pointerNode{}
+		}
+		n.elems[idx] = c
+	}
+	return c
+}
+
+func (n *pointerNode) leaf() bool {
+	return len(n.fields) == 0
+}
+
+// NewPointerSet compiles given JSON Pointers to PointerSet.
+func NewPointerSet(ptrs ...string) (*PointerSet, error) {
+	s := &PointerSet{}
+	for _, ptr := range ptrs {
+		if _, err := s.Add(ptr); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add adds JSON Pointer to set, returning index of its value in Extract result.
+func (s *PointerSet) Add(ptr string) (int, error) {
+	path, err := parsePointer(ptr)
+	if err != nil {
+		return 0, err
+	}
+	return s.AddPath(path...), nil
+}
+
+// AddPath adds key path to set, returning index of its value in Extract result.
+//
+// Path elements are object keys or array indexes as-is, without JSON Pointer
+// escaping.
+func (s *PointerSet) AddPath(path ...string) int {
+	n := &s.root
+	for _, tok := range path {
+		n = n.child(tok)
+	}
+	idx := s.n
+	n.targets = append(n.targets, idx)
+	s.n++
+	return idx
+}
+
+// Len returns count of pointers in set.
+func (s *PointerSet) Len() int {
+	return s.n
+}
+
+// Extract reads next value from Decoder and returns raw values referenced
+// by pointers, in order they were added to the set. Value is nil if pointer
+// target does not exist.
+//
+// Subtrees that are not referenced by any pointer are skipped without
+// decoding. For buffered Decoder, returned values reference underlying buffer.
+func (s *PointerSet) Extract(d *Decoder) ([]Raw, error) {
+	out := make([]Raw, s.n)
+	if err := s.extract(d, &s.root, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *PointerSet) extract(d *Decoder, n *pointerNode, out []Raw) error {
+	if len(n.targets) == 0 {
+		return s.extractChildren(d, n, out)
+	}
+
+	if err := d.skipSpace(); err != nil {
+		return err
+	}
+	raw, err := d.Raw()
+	if err != nil {
+		return err
+	}
+	if d.reader != nil {
+		// Raw references internal buffer that will be overwritten.
+		raw = append(Raw(nil), raw...)
+	}
+	for _, idx := range n.targets {
+		out[idx] = raw
+	}
+	if n.leaf() {
+		return nil
+	}
+	sub := d.sub(raw)
+	return s.extractChildren(&sub, n, out)
+}
+
+func (s *PointerSet) extractChildren(d *Decoder, n *pointerNode, out []Raw) error {
+	if n.leaf() {
+		return d.Skip()
+	}
+	switch d.Next() {
+	case Object:
+		iter, err := d.ObjIter()
+		if err != nil {
+			return err
+		}
+		for iter.Next() {
+			c, ok := n.fields[string(iter.Key())]
+			if !ok {
+				if err := d.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := s.extract(d, c, out); err != nil {
+				return errors.Wrapf(err, "%q", iter.Key())
+			}
+		}
+		return iter.Err()
+	case Array:
+		if len(n.elems) == 0 {
+			return d.Skip()
+		}
+		iter, err := d.ArrIter()
+		if err != nil {
+			return err
+		}
+		for i := 0; iter.Next(); i++ {
+			c, ok := n.elems[i]
+			if !ok {
+				if err := d.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := s.extract(d, c, out); err != nil {
+				return errors.Wrapf(err, "%d", i)
+			}
+		}
+		return iter.Err()
+	default:
+		return d.Skip()
+	}
+}
+
+// GetMany extracts values referenced by JSON Pointers from next value in
+// a single pass.
+//
+// Values are returned in order of pointers, value is nil if pointer
+// target does not exist. Use PointerSet to compile pointers once.
+func (d *Decoder) GetMany(ptrs ...string) ([]Raw, error) {
+	s, err := NewPointerSet(ptrs...)
+	if err != nil {
+		return nil, err
+	}
+	return s.Extract(d)
+}
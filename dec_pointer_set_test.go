@@ -0,0 +1,139 @@
+package jx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointerSet_Extract(t *testing.T) {
+	const input = `{
+	"a": {"b": [1, {"c": "d"}, [3]], "e": null},
+	"0": "zero",
+	"arr": ["x", "y"],
+	"m~n": true
+}`
+	ptrs := []string{
+		"/a/b/1/c",
+		"/a/b/1",
+		"/a/e",
+		"/a/b/2/0",
+		"/0",
+		"/arr/1",
+		"/arr/0",
+		"/m~0n",
+		"/a/b/1/c",
+		"/missing",
+		"/a/b/5",
+		"/arr/x",
+		"/a/e/f",
+		"",
+	}
+	expected := []string{
+		`"d"`,
+		`{"c": "d"}`,
+		`null`,
+		`3`,
+		`"zero"`,
+		`"y"`,
+		`"x"`,
+		`true`,
+		`"d"`,
+		``,
+		``,
+		``,
+		``,
+		input,
+	}
+	s, err := NewPointerSet(ptrs...)
+	require.NoError(t, err)
+	require.Equal(t, len(ptrs), s.Len())
+
+	t.Run("Extract", testBufferReader(input, func(t *testing.T, d *Decoder) {
+		values, err := s.Extract(d)
+		require.NoError(t, err)
+		require.Len(t, values, len(ptrs))
+		for i, v := range values {
+			require.Equal(t, expected[i], v.String(), ptrs[i])
+		}
+		require.Equal(t, Invalid, d.Next(), "value must be consumed")
+	}))
+	t.Run("GetMany", testBufferReader(input, func(t *testing.T, d *Decoder) {
+		values, err := d.GetMany("/arr/1", "/a/b/0")
+		require.NoError(t, err)
+		require.Equal(t, []Raw{Raw(`"y"`), Raw(`1`)}, values)
+	}))
+	t.Run("AddPath", func(t *testing.T) {
+		var s PointerSet
+		require.Equal(t, 0, s.AddPath("m~n"))
+		require.Equal(t, 1, s.AddPath("a", "b", "1", "c"))
+		values, err := s.Extract(DecodeStr(input))
+		require.NoError(t, err)
+		require.Equal(t, []Raw{Raw(`true`), Raw(`"d"`)}, values)
+	})
+	t.Run("InvalidPointer", func(t *testing.T) {
+		_, err := NewPointerSet("/a", "b")
+		require.ErrorIs(t, err, ErrInvalidPointer)
+		_, err = DecodeStr(input).GetMany("/~")
+		require.ErrorIs(t, err, ErrInvalidPointer)
+	})
+	t.Run("InvalidJSON", func(t *testing.T) {
+		for _, s := range []string{
+			`{"a": {"b": [1, {"c": "d"}, [3]], "e": nul}`,
+			`{"a": {"b": [1, {"c": "d" 1}]}}`,
+			`{"a": {"b": [1, {"c": "d"}, [3}}}`,
+			`{"a": {"b": [1, {"c": "d"}`,
+			`{"arr": [1 2]}`,
+			`{"a" 1}`,
+			`[`,
+		} {
+			_, err := DecodeStr(s).GetMany(ptrs...)
+			require.Error(t, err, s)
+		}
+	})
+}
+
+func TestPointerSet_Twitter(t *testing.T) {
+	runTestdataFile("twitter.json", t.Fatal, func(name string, data []byte) {
+		ptrs := []string{
+			"/statuses/0/user/screen_name",
+			"/statuses/3/id_str",
+			"/search_metadata/count",
+			"/statuses/99/text",
+		}
+		values, err := DecodeBytes(data).GetMany(ptrs...)
+		require.NoError(t, err)
+		for i, ptr := range ptrs {
+			d := DecodeBytes(data)
+			require.NoError(t, d.Pointer(ptr))
+			raw, err := d.Raw()
+			require.NoError(t, err)
+			require.Equal(t, raw, values[i], ptr)
+		}
+	})
+}
+
+func BenchmarkPointerSet_Extract(b *testing.B) {
+	runTestdataFile("twitter.json", b.Fatal, func(name string, data []byte) {
+		s, err := NewPointerSet(
+			"/statuses/0/user/screen_name",
+			"/statuses/3/id_str",
+			"/search_metadata/count",
+			"/statuses/99/text",
+		)
+		if err != nil {
+			b.Fatal(err)
+		}
+		d := GetDecoder()
+		defer PutDecoder(d)
+
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			d.ResetBytes(data)
+			if _, err := s.Extract(d); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package jx
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_Pointer(t *testing.T) {
+	const input = `{
+	"foo": ["bar", "baz"],
+	"": 0,
+	"a/b": 1,
+	"c%d": 2,
+	"e^f": 3,
+	"g|h": 4,
+	"i\\j": 5,
+	"k\"l": 6,
+	" ": 7,
+	"m~n": 8,
+	"nested": {"arr": [{"x": 1}, {"x": [10, 20, 30]}]}
+}`
+	for i, tt := range []struct {
+		Pointer string
+		Value   string
+	}{
+		// Examples from RFC 6901, Section 5.
+		{"/foo", `["bar", "baz"]`},
+		{"/foo/0", `"bar"`},
+		{"/", `0`},
+		{"/a~1b", `1`},
+		{"/c%d", `2`},
+		{"/e^f", `3`},
+		{"/g|h", `4`},
+		{"/i\\j", `5`},
+		{"/k\"l", `6`},
+		{"/ ", `7`},
+		{"/m~0n", `8`},
+
+		{"/nested/arr/1/x/2", `30`},
+		{"/nested/arr/0", `{"x": 1}`},
+	} {
+		tt := tt
+		t.Run(fmt.Sprintf("Test%d", i+1), testBufferReader(input, func(t *testing.T, d *Decoder) {
+			require.NoError(t, d.Pointer(tt.Pointer))
+			raw, err := d.Raw()
+			require.NoError(t, err)
+			require.Equal(t, tt.Value, raw.String())
+		}))
+	}
+	t.Run("State", testBufferReader(`{"a": [0, {"b": {"c": tru}}]}`, func(t *testing.T, d *Decoder) {
+		d.SetDisallowDuplicateKeys(true)
+		d.SetLimits(Limits{MaxDepth: 2})
+		require.NoError(t, d.Pointer("/a/1"))
+		require.Zero(t, d.depth)
+		require.Empty(t, d.path)
+		require.Empty(t, d.keys)
+
+		err := d.Obj(func(d *Decoder, key string) error {
+			return d.Obj(func(d *Decoder, key string) error {
+				_, err := d.Bool()
+				return err
+			})
+		})
+		var e *DecodeError
+		require.ErrorAs(t, err, &e)
+		require.Equal(t, "/b/c", e.Pointer())
+	}))
+	t.Run("Root", testBufferReader(`[1]`, func(t *testing.T, d *Decoder) {
+		require.NoError(t, d.Pointer(""))
+		require.Equal(t, Array, d.Next())
+	}))
+}
+
+func TestDecoder_PointerError(t *testing.T) {
+	const input = `{"foo": ["bar", "baz"], "num": 1, "obj": {"a": {}}}`
+	for _, ptr := range []string{
+		"/bar",
+		"/foo/2",
+		"/foo/-",
+		"/foo/01",
+		"/foo/bar",
+		"/foo/99999999999999999999999",
+		"/num/0",
+		"/obj/a/b",
+		"/obj/b",
+	} {
+		ptr := ptr
+		t.Run(ptr, testBufferReader(input, func(t *testing.T, d *Decoder) {
+			require.ErrorIs(t, d.Pointer(ptr), ErrPointerNotFound)
+		}))
+	}
+	for _, ptr := range []string{
+		"foo",
+		"/m~2n",
+		"/m~",
+	} {
+		ptr := ptr
+		t.Run(ptr, testBufferReader(input, func(t *testing.T, d *Decoder) {
+			require.ErrorIs(t, d.Pointer(ptr), ErrInvalidPointer)
+		}))
+	}
+	for _, s := range []string{
+		``,
+		`{"foo": [`,
+		`{"foo" 1}`,
+		`{"bar": 1, "foo": [tru]}`,
+		`{"foo": [nul, 1]}`,
+		`[`,
+	} {
+		s := s
+		t.Run(fmt.Sprintf("Invalid%q", s), testBufferReader(s, func(t *testing.T, d *Decoder) {
+			err := d.Pointer("/foo/1")
+			require.Error(t, err)
+			require.NotErrorIs(t, err, ErrPointerNotFound)
+		}))
+	}
+}
+
+func TestDecoder_PointerOTEL(t *testing.T) {
+	d := DecodeBytes(otelEx1)
+	require.NoError(t, d.Pointer("/Attributes/http.status_code"))
+	v, err := d.Int()
+	require.NoError(t, err)
+	require.Equal(t, 500, v)
+}
+
+func BenchmarkDecoder_Pointer(b *testing.B) {
+	d := GetDecoder()
+	defer PutDecoder(d)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(otelEx1)))
+	for i := 0; i < b.N; i++ {
+		d.ResetBytes(otelEx1)
+		if err := d.Pointer("/Resource/k8s.pod.uid"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
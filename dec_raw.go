@@ -12,17 +12,20 @@ type rawReader struct {
 	// if true, buf is reference to  *Decoder.buf.
 	captured bool
 	orig     io.Reader
+	// scratch is reused buffer for copy.
+	scratch []byte
 }
 
 func (r *rawReader) Read(p []byte) (n int, err error) {
 	if r.captured {
 		// Make a copy.
-		r.buf = append([]byte(nil), r.buf...)
+		r.buf = append(r.scratch[:0], r.buf...)
 		r.captured = false
 	}
 	n, err = r.orig.Read(p)
 	if n > 0 {
 		r.buf = append(r.buf, p[:n]...)
+		r.scratch = r.buf
 	}
 	return n, err
 }
@@ -31,12 +34,26 @@ func (r *rawReader) Read(p []byte) (n int, err error) {
 //
 // Do not retain returned value, it references underlying buffer.
 func (d *Decoder) Raw() (Raw, error) {
+	return d.rawTo(nil)
+}
+
+// rawTo is Raw that uses rr to capture value in streaming mode.
+//
+// If rr is nil, new reader is allocated, otherwise rr buffer is reused.
+func (d *Decoder) rawTo(rr *rawReader) (Raw, error) {
+	if d.at != nil {
+		return d.rawAt()
+	}
 	start := d.head
 	if orig := d.reader; orig != nil {
-		rr := &rawReader{
+		if rr == nil {
+			rr = new(rawReader)
+		}
+		*rr = rawReader{
 			buf:      d.buf[start:d.tail],
 			captured: true,
 			orig:     orig,
+			scratch:  rr.scratch,
 		}
 		d.reader = rr
 		defer func() {
@@ -1,6 +1,7 @@
 package jx
 
 import (
+	"bytes"
 	"io"
 	"math/bits"
 )
@@ -11,18 +12,34 @@ func (d *Decoder) Next() Type {
 	if err == nil {
 		d.unread()
 	}
+	if d.relaxed {
+		return relaxedTypes[v]
+	}
 	return types[v]
 }
 
+// spaceSet is set of whitespace characters, slash is possible start of
+// comment in relaxed mode.
 var spaceSet = [256]byte{
 	' ': 1, '\n': 1, '\t': 1, '\r': 1,
+	'/': 2,
 }
 
 func (d *Decoder) consume(c byte) (err error) {
+readBuf:
 	for {
 		buf := d.buf[d.head:d.tail]
 		for i, got := range buf {
 			switch spaceSet[got] {
+			case 2:
+				if d.relaxed {
+					d.head += i + 1
+					if err := d.skipComment(); err != nil {
+						return err
+					}
+					continue readBuf
+				}
+				fallthrough
 			default:
 				if c != got {
 					return badToken(got, d.offset()+i)
@@ -53,10 +70,20 @@ func (d *Decoder) more() (byte, error) {
 
 // next reads next non-whitespace token or error.
 func (d *Decoder) next() (byte, error) {
+readBuf:
 	for {
 		buf := d.buf[d.head:d.tail]
 		for i, c := range buf {
 			switch spaceSet[c] {
+			case 2:
+				if d.relaxed {
+					d.head += i + 1
+					if err := d.skipComment(); err != nil {
+						return 0, err
+					}
+					continue readBuf
+				}
+				fallthrough
 			default:
 				d.head += i + 1
 				return c, nil
@@ -97,52 +124,138 @@ func (d *Decoder) byte() (byte, error) {
 }
 
 func (d *Decoder) read() error {
+	if d.truncated {
+		d.head = d.tail
+		return ErrMaxBytes
+	}
 	if d.reader == nil {
 		d.head = d.tail
 		return io.EOF
 	}
 
-	n, err := d.reader.Read(d.buf)
+	n, err := d.reader.Read(d.readBuf(d.keep(), 1))
 	switch err {
 	case nil:
 	case io.EOF:
 		if n > 0 {
 			break
 		}
-		fallthrough
+		// Buffer is consumed, as for byte slice.
+		d.head = d.tail
+		return err
 	default:
 		return err
 	}
 
-	d.streamOffset += d.tail
-	d.head = 0
-	d.tail = n
+	d.tail += n
+	d.limitRead()
 	return nil
 }
 
 func (d *Decoder) readAtLeast(min int) error {
+	if d.truncated {
+		d.head = d.tail
+		return ErrMaxBytes
+	}
 	if d.reader == nil {
 		d.head = d.tail
 		return io.ErrUnexpectedEOF
 	}
 
-	if need := min - len(d.buf); need > 0 {
-		d.buf = append(d.buf, make([]byte, need)...)
+	n, err := io.ReadAtLeast(d.reader, d.readBuf(d.keep(), min), min)
+	if n > 0 {
+		// Keep data read before error, reader does not return it again.
+		d.tail += n
+		d.limitRead()
 	}
-	n, err := io.ReadAtLeast(d.reader, d.buf, min)
 	if err != nil {
 		if err == io.EOF && n == 0 {
 			return io.ErrUnexpectedEOF
 		}
 		return err
 	}
-
-	d.streamOffset += d.tail
-	d.head = 0
-	d.tail = n
+	if d.tail-d.head < min {
+		return ErrMaxBytes
+	}
 	return nil
 }
 
+// keep returns offset in buffer of first byte that should be kept by read,
+// which is start of first checkpoint or end of buffer.
+func (d *Decoder) keep() int {
+	if len(d.pins) > 0 {
+		return d.pins[0].offset - d.streamOffset
+	}
+	return d.tail
+}
+
+// readBuf consumes buffer, keeping bytes from keep offset, and returns free
+// space after them for read of at least min bytes.
+//
+// Kept bytes are read after if there is space, otherwise they are moved to
+// start of buffer, that grows twice if they take more than half of it. Kept
+// bytes are copied to spare buffer instead of move if current one is
+// referenced by rawReader, which copies it on read.
+func (d *Decoder) readBuf(keep, min int) []byte {
+	kept := d.tail - keep
+	if kept > 0 && len(d.buf)-d.tail >= min {
+		d.head = d.tail
+		return d.buf[d.tail:]
+	}
+	size := len(d.buf)
+	if size < 2*kept {
+		size = 2 * kept
+	}
+	if size < kept+min {
+		size = kept + min
+	}
+	d.streamLines, d.streamLineStart, d.streamPrevLineStart = d.bufLines(keep)
+	switch {
+	case kept == 0:
+		if need := size - len(d.buf); need > 0 {
+			d.buf = append(d.buf, make([]byte, need)...)
+		}
+	case size > len(d.buf) || d.borrowed():
+		if cap(d.spare) < size {
+			d.spare = make([]byte, size)
+		}
+		d.spare = d.spare[:cap(d.spare)]
+		copy(d.spare, d.buf[keep:d.tail])
+		d.buf, d.spare = d.spare, d.buf
+	default:
+		copy(d.buf, d.buf[keep:d.tail])
+	}
+	d.streamOffset += keep
+	d.head, d.tail = kept, kept
+	return d.buf[kept:]
+}
+
+// borrowed reports whether buffer is referenced by rawReader.
+func (d *Decoder) borrowed() bool {
+	r, ok := d.reader.(*rawReader)
+	return ok && r.captured
+}
+
+// bufLines returns count of new lines, line start offset and previous line
+// start offset at the end of first n bytes of current buffer.
+//
+// Should be called before buffer is overwritten.
+func (d *Decoder) bufLines(n int) (lines, lineStart, prevLineStart int) {
+	lines, lineStart, prevLineStart = d.streamLines, d.streamLineStart, d.streamPrevLineStart
+	buf := d.buf[:n]
+	if n := bytes.Count(buf, []byte{'\n'}); n > 0 {
+		lines += n
+		last := bytes.LastIndexByte(buf, '\n')
+		if n > 1 {
+			prevLineStart = d.streamOffset + bytes.LastIndexByte(buf[:last], '\n') + 1
+		} else {
+			prevLineStart = lineStart
+		}
+		lineStart = d.streamOffset + last + 1
+	}
+	return lines, lineStart, prevLineStart
+}
+
 func (d *Decoder) unread() { d.head-- }
 
 func (d *Decoder) readExact4(b *[4]byte) error {
@@ -0,0 +1,126 @@
+package jx
+
+import (
+	"io"
+
+	"github.com/go-faster/errors"
+)
+
+// readerAt reads io.ReaderAt sequentially from absolute offset.
+type readerAt struct {
+	r    io.ReaderAt
+	off  int64
+	size int64
+}
+
+func (r *readerAt) Read(p []byte) (int, error) {
+	if r.off >= r.size {
+		return 0, io.EOF
+	}
+	if rem := r.size - r.off; int64(len(p)) > rem {
+		p = p[:rem]
+	}
+	n, err := r.r.ReadAt(p, r.off)
+	r.off += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// DecodeReaderAt creates a Decoder that reads size bytes of json from
+// io.ReaderAt, like *os.File.
+//
+// Input is addressed by absolute offset, so Capture and Seek re-read input
+// instead of buffering it, and Raw returns slice of internal buffer if value
+// fits into it and reads value directly otherwise.
+func DecodeReaderAt(r io.ReaderAt, size int64, bufSize int) *Decoder {
+	if bufSize <= 0 {
+		bufSize = defaultBuf
+	}
+	at := &readerAt{r: r, size: size}
+	return &Decoder{
+		reader: at,
+		at:     at,
+		buf:    make([]byte, bufSize),
+	}
+}
+
+// Offset returns absolute offset of next byte of input.
+func (d *Decoder) Offset() int {
+	return d.offset()
+}
+
+// Seek positions decoder at absolute offset of input, like one returned by
+// Offset. Decoding continues at top level, so offset should be start of
+// json value. Active checkpoints are released.
+//
+// Supported only by decoders of byte slice and io.ReaderAt. For io.ReaderAt,
+// lines of DecodeError are counted from offset if it is outside of buffer.
+func (d *Decoder) Seek(offset int) error {
+	switch {
+	case d.at != nil:
+		if offset < 0 || int64(offset) > d.at.size {
+			return errors.Errorf("offset %d is out of range", offset)
+		}
+		if rel := offset - d.streamOffset; rel >= 0 && rel <= d.tail {
+			// Offset is in buffer.
+			d.head = rel
+			break
+		}
+		d.at.off = int64(offset)
+		d.streamOffset = offset
+		d.streamLines, d.streamLineStart, d.streamPrevLineStart = 0, offset, offset
+		d.head, d.tail = 0, 0
+		d.truncated = false
+		d.limitRead()
+	case d.reader == nil:
+		if offset < 0 || offset > d.tail {
+			return errors.Errorf("offset %d is out of range", offset)
+		}
+		d.head = offset
+	default:
+		return errors.New("seek is not supported for io.Reader")
+	}
+	d.depth = 0
+	d.path = d.path[:0]
+	d.keys = d.keys[:0]
+	d.tokens = d.tokens[:0]
+	d.unpin()
+	d.indexStop = 0
+	return nil
+}
+
+// rawAt is Raw for io.ReaderAt.
+func (d *Decoder) rawAt() (Raw, error) {
+	start := d.offset()
+	if err := d.Skip(); err != nil {
+		return nil, errors.Wrap(err, "skip")
+	}
+	if rel := start - d.streamOffset; rel >= 0 {
+		return d.buf[rel:d.head], nil
+	}
+	// Buffer was overwritten, read value again.
+	raw := make([]byte, d.offset()-start)
+	if n, err := d.at.r.ReadAt(raw, int64(start)); n < len(raw) {
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, errors.Wrap(err, "read")
+	}
+	return raw, nil
+}
+
+// refillAt restores buffer of io.ReaderAt that starts at streamOffset and
+// ends at tail, if it was overwritten.
+func (d *Decoder) refillAt(streamOffset, tail int) error {
+	if d.streamOffset == streamOffset {
+		return nil
+	}
+	d.at.off = int64(streamOffset)
+	d.streamOffset = streamOffset
+	if _, err := io.ReadFull(d.at, d.buf[:tail]); err != nil {
+		return errors.Wrap(err, "read")
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package jx
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeReaderAt(t *testing.T) {
+	runTestdata(t.Fatal, func(name string, data []byte) {
+		t.Run(name, func(t *testing.T) {
+			for _, bufSize := range []int{1, 7, 512} {
+				d := DecodeReaderAt(bytes.NewReader(data), int64(len(data)), bufSize)
+				expected := DecodeBytes(data)
+				if !Valid(data) {
+					continue
+				}
+				raw, err := d.Raw()
+				require.NoError(t, err)
+				exp, err := expected.Raw()
+				require.NoError(t, err)
+				require.Equal(t, exp, raw)
+			}
+		})
+	})
+}
+
+func TestDecoder_Seek(t *testing.T) {
+	const input = `[{"a":1}, "foo", [1, 2, 3], true]`
+	decoders := map[string]func() *Decoder{
+		"Bytes": func() *Decoder { return DecodeStr(input) },
+		"ReaderAt": func() *Decoder {
+			return DecodeReaderAt(strings.NewReader(input), int64(len(input)), 4)
+		},
+	}
+	for name, newDecoder := range decoders {
+		newDecoder := newDecoder
+		t.Run(name, func(t *testing.T) {
+			d := newDecoder()
+			var offsets []int
+			require.NoError(t, d.Arr(func(d *Decoder) error {
+				offsets = append(offsets, d.Offset())
+				return d.Skip()
+			}))
+			require.Len(t, offsets, 4)
+
+			elems, err := rawElems(DecodeStr(input), nil)
+			require.NoError(t, err)
+			for _, i := range []int{2, 0, 3, 1, 2} {
+				require.NoError(t, d.Seek(offsets[i]))
+				raw, err := d.Raw()
+				require.NoError(t, err)
+				require.Equal(t, elems[i], trimSpace(raw))
+			}
+
+			// Out of range.
+			require.Error(t, d.Seek(-1))
+			require.Error(t, d.Seek(len(input)+1))
+			require.NoError(t, d.Seek(len(input)))
+			require.ErrorIs(t, d.Skip(), io.EOF)
+		})
+	}
+	t.Run("Reader", func(t *testing.T) {
+		d := Decode(strings.NewReader(input), 4)
+		require.Error(t, d.Seek(0))
+	})
+}
+
+func TestDecodeReaderAt_Capture(t *testing.T) {
+	const input = `{"a":[1,2,3,4,5,6,7,8,9],"b":"long string value"}`
+	d := DecodeReaderAt(strings.NewReader(input), int64(len(input)), 4)
+	require.NoError(t, d.Obj(func(d *Decoder, key string) error {
+		var first Raw
+		if err := d.Capture(func(d *Decoder) error {
+			v, err := d.Raw()
+			first = append(first, v...)
+			return err
+		}); err != nil {
+			return err
+		}
+		v, err := d.Raw()
+		require.NoError(t, err)
+		require.Equal(t, first, v)
+		return nil
+	}))
+	require.ErrorIs(t, d.Skip(), io.EOF)
+}
+
+func TestDecodeReaderAt_Error(t *testing.T) {
+	const input = "[1,\n2,\n  x]"
+	d := DecodeReaderAt(strings.NewReader(input), int64(len(input)), 2)
+	expected := DecodeStr(input)
+	err := d.Skip()
+	require.Error(t, err)
+	expErr := expected.Skip()
+	require.Equal(t, expErr.Error(), err.Error())
+
+	// Size limits input.
+	d = DecodeReaderAt(strings.NewReader(`[1, 2]`), 4, 2)
+	require.ErrorIs(t, d.Skip(), io.EOF)
+}
+
+func BenchmarkDecodeReaderAt_Raw(b *testing.B) {
+	data := benchData
+	r := bytes.NewReader(data)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		d := DecodeReaderAt(r, int64(len(data)), 4096)
+		if err := d.Arr(func(d *Decoder) error {
+			_, err := d.Raw()
+			return err
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
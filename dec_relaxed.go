@@ -0,0 +1,432 @@
+package jx
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/go-faster/errors"
+)
+
+// SetRelaxed enables relaxed JSON5-style parsing mode, useful for
+// human-written configuration files. Relaxed mode allows:
+//
+//   - "//" and "/* */" comments where whitespace is allowed
+//   - trailing commas in objects and arrays
+//   - unquoted identifier object keys, like {foo: 1}
+//   - single-quoted strings, like 'foo'
+//   - hex numbers, like 0x1F and -0xff
+//   - NaN, Infinity and -Infinity
+//
+// Raw and Num return relaxed literals as-is, BigInt and BigFloat do not
+// support relaxed numbers.
+//
+// Mode is kept on Reset and ResetBytes, but reset on PutDecoder.
+func (d *Decoder) SetRelaxed(v bool) {
+	d.relaxed = v
+}
+
+var relaxedTypes []Type
+
+func init() {
+	relaxedTypes = make([]Type, len(types))
+	copy(relaxedTypes, types)
+	relaxedTypes['\''] = String
+	relaxedTypes['N'] = Number
+	relaxedTypes['I'] = Number
+}
+
+// skipComment skips comment, assuming that first slash was consumed.
+func (d *Decoder) skipComment() error {
+	c, err := d.byte()
+	if err != nil {
+		return err
+	}
+	switch c {
+	case '/':
+		for {
+			if i := bytes.IndexByte(d.buf[d.head:d.tail], '\n'); i >= 0 {
+				d.head += i + 1
+				return nil
+			}
+			d.head = d.tail
+			if err := d.read(); err != nil {
+				if err == io.EOF {
+					// Comment at the end of input.
+					return nil
+				}
+				return err
+			}
+		}
+	case '*':
+		var prev byte
+		for {
+			c, err := d.byte()
+			if err != nil {
+				return errors.Wrap(err, "unterminated comment")
+			}
+			if prev == '*' && c == '/' {
+				return nil
+			}
+			prev = c
+		}
+	default:
+		return badToken(c, d.offset()-1)
+	}
+}
+
+// trailingComma reports whether comma is followed by end bracket in relaxed
+// mode, consuming the bracket.
+func (d *Decoder) trailingComma(end byte) (bool, error) {
+	if !d.relaxed {
+		return false, nil
+	}
+	c, err := d.more()
+	if err != nil {
+		return false, err
+	}
+	if c == end {
+		return true, nil
+	}
+	d.unread()
+	return false, nil
+}
+
+const (
+	identStart byte = 1
+	identPart  byte = 2
+)
+
+// identSet is set of identifier characters.
+//
+// Non-ASCII bytes are accepted as-is.
+var identSet = func() (r [256]byte) {
+	for c := 0; c < len(r); c++ {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == '$', c >= 0x80:
+			r[c] = identStart
+		case c >= '0' && c <= '9':
+			r[c] = identPart
+		}
+	}
+	return r
+}()
+
+// key reads object key, which can be an identifier in relaxed mode.
+func (d *Decoder) key(v value) (value, error) {
+	if !d.relaxed {
+		return d.str(v)
+	}
+	c, err := d.more()
+	if err != nil {
+		return value{}, err
+	}
+	d.unread()
+	if identSet[c] != identStart {
+		return d.str(v)
+	}
+
+	start := d.offset()
+	for {
+		buf := d.buf[d.head:d.tail]
+		for i, c := range buf {
+			if identSet[c] != 0 {
+				continue
+			}
+			if err := d.checkStrLen(d.offset() + i - start); err != nil {
+				return value{}, err
+			}
+			d.head += i
+			if v.raw {
+				return value{buf: buf[:i], raw: true}, nil
+			}
+			return value{buf: append(v.buf, buf[:i]...)}, nil
+		}
+		v.buf = append(v.buf, buf...)
+		v.raw = false
+		if err := d.checkStrLen(d.offset() + len(buf) - start); err != nil {
+			return value{}, err
+		}
+		if err := d.read(); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return value{}, err
+		}
+	}
+}
+
+// strSingle reads single-quoted string, assuming that quote was consumed.
+func (d *Decoder) strSingle(v value) (value, error) {
+	start := d.offset()
+	for {
+		c, err := d.byte()
+		if err != nil {
+			return value{}, err
+		}
+		switch {
+		case c == '\'':
+			if err := d.checkStrLen(d.offset() - 1 - start); err != nil {
+				return value{}, err
+			}
+			return value{buf: v.buf}, nil
+		case c == '\\':
+			c, err := d.byte()
+			if err != nil {
+				return value{}, err
+			}
+			if c == '\'' {
+				v.buf = append(v.buf, c)
+				break
+			}
+			if v, err = d.escapedChar(v, c); err != nil {
+				return v, errors.Wrap(err, "escape")
+			}
+		case c < ' ':
+			return value{}, badToken(c, d.offset()-1)
+		default:
+			v.buf = append(v.buf, c)
+		}
+		if err := d.checkStrLen(d.offset() - start); err != nil {
+			return value{}, err
+		}
+	}
+}
+
+// relaxedNumSet is set of characters that can be in relaxed number literal.
+var relaxedNumSet = func() (r [256]bool) {
+	for c := 0; c < len(r); c++ {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '.', c == '-', c == '+':
+			r[c] = true
+		}
+	}
+	return r
+}()
+
+// relaxedNum reads number literal in relaxed mode, appending it to b.
+func (d *Decoder) relaxedNum(b []byte) (_ []byte, offset int, _ error) {
+	if _, err := d.more(); err != nil {
+		return nil, 0, err
+	}
+	d.unread()
+
+	offset = d.offset()
+	start := len(b)
+	for {
+		buf := d.buf[d.head:d.tail]
+		for i, c := range buf {
+			if relaxedNumSet[c] {
+				continue
+			}
+			b = append(b, buf[:i]...)
+			d.head += i
+			if err := d.checkNumLen(len(b) - start); err != nil {
+				return nil, 0, err
+			}
+			return b, offset, checkRelaxedNum(b[start:], offset)
+		}
+		b = append(b, buf...)
+		d.head = d.tail
+		if err := d.checkNumLen(len(b) - start); err != nil {
+			return nil, 0, err
+		}
+		if err := d.read(); err != nil {
+			if err == io.EOF {
+				return b, offset, checkRelaxedNum(b[start:], offset)
+			}
+			return nil, 0, err
+		}
+	}
+}
+
+// relaxedNumKind is kind of relaxed number literal.
+type relaxedNumKind byte
+
+const (
+	relaxedNumJSON relaxedNumKind = iota
+	relaxedNumHex
+	relaxedNumNaN
+	relaxedNumInf
+)
+
+// splitRelaxedNum splits number literal to sign, kind and literal without
+// sign and hex prefix.
+func splitRelaxedNum(lit []byte) (neg bool, kind relaxedNumKind, digits []byte) {
+	if len(lit) > 0 && lit[0] == '-' {
+		neg, digits = true, lit[1:]
+	} else {
+		digits = lit
+	}
+	switch {
+	case string(digits) == "NaN":
+		return neg, relaxedNumNaN, digits
+	case string(digits) == "Infinity":
+		return neg, relaxedNumInf, digits
+	case len(digits) > 2 && digits[0] == '0' && (digits[1] == 'x' || digits[1] == 'X'):
+		return neg, relaxedNumHex, digits[2:]
+	default:
+		return neg, relaxedNumJSON, lit
+	}
+}
+
+// checkRelaxedNum validates relaxed number literal at given offset.
+func checkRelaxedNum(lit []byte, offset int) error {
+	if len(lit) == 0 {
+		return errors.New("empty number")
+	}
+	_, kind, digits := splitRelaxedNum(lit)
+	switch kind {
+	case relaxedNumNaN, relaxedNumInf:
+		return nil
+	case relaxedNumHex:
+		for i, c := range digits {
+			if hexSet[c] == 0 {
+				return badToken(c, offset+len(lit)-len(digits)+i)
+			}
+		}
+		return nil
+	}
+	if c := lit[0]; c != '-' && (c < '0' || c > '9') {
+		return badToken(c, offset)
+	}
+
+	// Validate JSON number.
+	n := DecodeBytes(lit)
+	if err := n.skipNum(); err != nil {
+		if e, ok := errors.Into[*badTokenErr](err); ok {
+			return badToken(e.Token, offset+e.Offset)
+		}
+		return err
+	}
+	if n.head != len(lit) {
+		return badToken(lit[n.head], offset+n.head)
+	}
+	return nil
+}
+
+// skipRelaxedNum skips number literal in relaxed mode.
+func (d *Decoder) skipRelaxedNum() error {
+	var buf [32]byte
+	_, _, err := d.relaxedNum(buf[:0])
+	return err
+}
+
+// relaxedFloat reads float in relaxed mode.
+func (d *Decoder) relaxedFloat(size int) (float64, error) {
+	var buf [32]byte
+	lit, offset, err := d.relaxedNum(buf[:0])
+	if err != nil {
+		return 0, err
+	}
+	neg, kind, digits := splitRelaxedNum(lit)
+	switch kind {
+	case relaxedNumNaN:
+		return math.NaN(), nil
+	case relaxedNumInf:
+		if neg {
+			return math.Inf(-1), nil
+		}
+		return math.Inf(1), nil
+	case relaxedNumHex:
+		v, err := strconv.ParseUint(string(digits), 16, 64)
+		if err != nil {
+			return 0, errors.Wrapf(errOverflow, "hex number at %d", offset)
+		}
+		f := float64(v)
+		if neg {
+			f = -f
+		}
+		return f, nil
+	default:
+		f, err := strconv.ParseFloat(string(lit), size)
+		if err != nil {
+			return 0, errors.Wrapf(errOverflow, "number at %d", offset)
+		}
+		return f, nil
+	}
+}
+
+// relaxedUInt reads integer in relaxed mode, returning its sign
+// and magnitude.
+func (d *Decoder) relaxedUInt() (neg bool, _ uint64, _ error) {
+	var buf [32]byte
+	lit, offset, err := d.relaxedNum(buf[:0])
+	if err != nil {
+		return false, 0, err
+	}
+	neg, kind, digits := splitRelaxedNum(lit)
+	base := 10
+	switch kind {
+	case relaxedNumNaN, relaxedNumInf:
+		return false, 0, errors.Errorf("unexpected %s at %d", digits, offset)
+	case relaxedNumHex:
+		base = 16
+	default:
+		if neg {
+			digits = digits[1:]
+		}
+		if i := bytes.IndexAny(digits, ".eE"); i >= 0 {
+			err := badToken(digits[i], offset+len(lit)-len(digits)+i)
+			return false, 0, errors.Wrap(err, "unexpected floating point character")
+		}
+	}
+	v, err := strconv.ParseUint(string(digits), base, 64)
+	if err != nil {
+		return false, 0, errOverflow
+	}
+	return neg, v, nil
+}
+
+// relaxedUIntMax reads unsigned integer that is not greater than max
+// in relaxed mode.
+func (d *Decoder) relaxedUIntMax(max uint64) (uint64, error) {
+	neg, v, err := d.relaxedUInt()
+	if err != nil {
+		return 0, err
+	}
+	if neg && v != 0 {
+		return 0, errors.New("negative unsigned integer")
+	}
+	if v > max {
+		return 0, errOverflow
+	}
+	return v, nil
+}
+
+// relaxedIntMax reads integer in range [-max-1, max] in relaxed mode.
+func (d *Decoder) relaxedIntMax(max uint64) (int64, error) {
+	neg, v, err := d.relaxedUInt()
+	if err != nil {
+		return 0, err
+	}
+	if neg {
+		if v > max+1 {
+			return 0, errOverflow
+		}
+		return -int64(v), nil
+	}
+	if v > max {
+		return 0, errOverflow
+	}
+	return int64(v), nil
+}
+
+// skipRelaxed skips value that is valid only in relaxed mode, assuming that
+// first character c was consumed.
+func (d *Decoder) skipRelaxed(c byte) error {
+	d.unread()
+	switch c {
+	case '\'':
+		if _, err := d.str(value{raw: true}); err != nil {
+			return errors.Wrap(err, "str")
+		}
+		return nil
+	case 'N', 'I':
+		return d.skipRelaxedNum()
+	default:
+		return badToken(c, d.offset())
+	}
+}
@@ -0,0 +1,283 @@
+package jx
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_SetRelaxed(t *testing.T) {
+	for i, tt := range []struct {
+		Input  string
+		Expect string
+	}{
+		{`{"a":1}`, `{"a":1}`},
+		{`// comment
+{"a":1}`, `{"a":1}`},
+		{`/* comment */ {/* key */ "a" /* colon */ : /* value */ 1 /* end */} // comment`, `{"a":1}`},
+		{`{"a":1,}`, `{"a":1}`},
+		{`[1, 2, ]`, `[1,2]`},
+		{`[[],{},]`, `[[],{}]`},
+		{`{a: 1, _b$: 2, c1: 3}`, `{"a":1,"_b$":2,"c1":3}`},
+		{`{'a': 'b"c', "d": 'e\'f\n'}`, `{"a":"b\"c","d":"e'f\n"}`},
+		{`[0x1F, -0xff, 0X10]`, `[31,-255,16]`},
+		{`{n: NaN, p: Infinity, m: -Infinity}`, `{"n":"NaN","p":"Infinity","m":"-Infinity"}`},
+		{`[1.5,-2e3,0]`, `[1.5,-2e3,0]`},
+		{`{
+	// Server config.
+	host: 'localhost', /* default */
+	port: 0x1F90,
+	tags: ['a', 'b',],
+}`, `{"host":"localhost","port":8080,"tags":["a","b"]}`},
+	} {
+		tt := tt
+		t.Run(fmt.Sprintf("Test%d", i+1), func(t *testing.T) {
+			t.Run("Validate", testBufferReader(tt.Input, func(t *testing.T, d *Decoder) {
+				d.SetRelaxed(true)
+				require.NoError(t, d.Validate())
+			}))
+			t.Run("Decode", testBufferReader(tt.Input, func(t *testing.T, d *Decoder) {
+				d.SetRelaxed(true)
+				e := GetEncoder()
+				defer PutEncoder(e)
+				require.NoError(t, relaxedReencode(d, e))
+				require.Equal(t, tt.Expect, e.String())
+			}))
+			t.Run("Strict", func(t *testing.T) {
+				if tt.Input == tt.Expect {
+					t.Skip("Valid json")
+				}
+				require.False(t, Valid([]byte(tt.Input)))
+			})
+		})
+	}
+}
+
+// relaxedReencode decodes value in relaxed mode and encodes it as json,
+// writing NaN and Infinity as strings.
+func relaxedReencode(d *Decoder, e *Encoder) error {
+	switch d.Next() {
+	case Object:
+		e.ObjStart()
+		iter, err := d.ObjIter()
+		if err != nil {
+			return err
+		}
+		for iter.Next() {
+			e.FieldStart(string(iter.Key()))
+			if err := relaxedReencode(d, e); err != nil {
+				return err
+			}
+		}
+		e.ObjEnd()
+		return iter.Err()
+	case Array:
+		e.ArrStart()
+		if err := d.Arr(func(d *Decoder) error {
+			return relaxedReencode(d, e)
+		}); err != nil {
+			return err
+		}
+		e.ArrEnd()
+		return nil
+	case String:
+		s, err := d.Str()
+		if err != nil {
+			return err
+		}
+		e.Str(s)
+		return nil
+	case Number:
+		raw, err := d.Raw()
+		if err != nil {
+			return err
+		}
+		num := DecodeBytes(raw)
+		num.SetRelaxed(true)
+		f, err := num.Float64()
+		if err != nil {
+			return err
+		}
+		switch {
+		case math.IsNaN(f) || math.IsInf(f, 0):
+			e.Str(raw.String())
+		case bytes.ContainsAny(raw, "xX"):
+			e.Int64(int64(f))
+		default:
+			e.Raw(raw)
+		}
+		return nil
+	default:
+		return d.Skip()
+	}
+}
+
+func TestDecoder_RelaxedNumbers(t *testing.T) {
+	t.Run("Int", func(t *testing.T) {
+		for _, tt := range []struct {
+			Input  string
+			Expect int64
+		}{
+			{`0x7f`, 127},
+			{`-0x80`, -128},
+			{`0XfF`, 255},
+			{`-0`, 0},
+			{`100`, 100},
+			{`-100 // comment`, -100},
+		} {
+			tt := tt
+			t.Run(tt.Input, testBufferReader(tt.Input, func(t *testing.T, d *Decoder) {
+				d.SetRelaxed(true)
+				v, err := d.Int64()
+				require.NoError(t, err)
+				require.Equal(t, tt.Expect, v)
+			}))
+		}
+		for _, input := range []string{`0x`, `0xfg`, `NaN`, `Infinity`, `1.5`, `1e3`, `0x8000000000000000`, `--1`, `+1`} {
+			input := input
+			t.Run(input, testBufferReader(input, func(t *testing.T, d *Decoder) {
+				d.SetRelaxed(true)
+				_, err := d.Int64()
+				require.Error(t, err)
+			}))
+		}
+	})
+	t.Run("Overflow", func(t *testing.T) {
+		for _, tt := range []struct {
+			Input string
+			Do    func(d *Decoder) error
+		}{
+			{`0x100`, decoderOnlyError((*Decoder).UInt8)},
+			{`-0x81`, decoderOnlyError((*Decoder).Int8)},
+			{`0x80`, decoderOnlyError((*Decoder).Int8)},
+			{`-0x1`, decoderOnlyError((*Decoder).UInt32)},
+			{`0x10000`, decoderOnlyError((*Decoder).UInt16)},
+		} {
+			d := DecodeStr(tt.Input)
+			d.SetRelaxed(true)
+			require.Error(t, tt.Do(d), tt.Input)
+		}
+		d := DecodeStr(`0xffffffffffffffff`)
+		d.SetRelaxed(true)
+		v, err := d.UInt64()
+		require.NoError(t, err)
+		require.Equal(t, uint64(math.MaxUint64), v)
+	})
+	t.Run("Float", func(t *testing.T) {
+		for _, tt := range []struct {
+			Input  string
+			Expect float64
+		}{
+			{`Infinity`, math.Inf(1)},
+			{`-Infinity`, math.Inf(-1)},
+			{`0x10`, 16},
+			{`-1.5e2`, -150},
+		} {
+			tt := tt
+			t.Run(tt.Input, testBufferReader(tt.Input, func(t *testing.T, d *Decoder) {
+				d.SetRelaxed(true)
+				v, err := d.Float64()
+				require.NoError(t, err)
+				require.Equal(t, tt.Expect, v)
+			}))
+		}
+		d := DecodeStr(`NaN`)
+		d.SetRelaxed(true)
+		v, err := d.Float32()
+		require.NoError(t, err)
+		require.True(t, math.IsNaN(float64(v)))
+	})
+	t.Run("Num", func(t *testing.T) {
+		d := DecodeStr(`[0x1F, -Infinity]`)
+		d.SetRelaxed(true)
+		var nums []string
+		require.NoError(t, d.Arr(func(d *Decoder) error {
+			n, err := d.Num()
+			nums = append(nums, n.String())
+			return err
+		}))
+		require.Equal(t, []string{"0x1F", "-Infinity"}, nums)
+	})
+}
+
+func TestDecoder_RelaxedErrors(t *testing.T) {
+	for _, input := range []string{
+		`/* unterminated`,
+		`{"a":1} /* unterminated`,
+		`/x 1`,
+		`{a b: 1}`,
+		`{1a: 1}`,
+		`[,]`,
+		`[1,,]`,
+		`{,}`,
+		`{"a":1,,}`,
+		`'unterminated`,
+		"'a\x01'",
+		`0x`,
+		`0xZ`,
+		`Nan`,
+		`Infinit`,
+		`+Infinity`,
+		`.5`,
+		`01`,
+	} {
+		input := input
+		t.Run(input, testBufferReader(input, func(t *testing.T, d *Decoder) {
+			d.SetRelaxed(true)
+			require.Error(t, d.Validate())
+		}))
+	}
+}
+
+func TestDecoder_RelaxedIter(t *testing.T) {
+	t.Run("ArrIter", testBufferReader(`[1, 2, /* 3 */ ]`, func(t *testing.T, d *Decoder) {
+		d.SetRelaxed(true)
+		iter, err := d.ArrIter()
+		require.NoError(t, err)
+		var n int
+		for iter.Next() {
+			require.NoError(t, d.Skip())
+			n++
+		}
+		require.NoError(t, iter.Err())
+		require.Equal(t, 2, n)
+	}))
+	t.Run("Elem", testBufferReader(`[1, 2,]`, func(t *testing.T, d *Decoder) {
+		d.SetRelaxed(true)
+		var n int
+		for {
+			ok, err := d.Elem()
+			require.NoError(t, err)
+			if !ok {
+				break
+			}
+			require.NoError(t, d.Skip())
+			n++
+		}
+		require.Equal(t, 2, n)
+	}))
+	t.Run("Limits", testBufferReader(`{abcdef: 'abcdef', b: 0x12345}`, func(t *testing.T, d *Decoder) {
+		d.SetRelaxed(true)
+		d.SetLimits(Limits{MaxStrLen: 5})
+		require.ErrorIs(t, d.Validate(), ErrMaxStrLen)
+	}))
+	t.Run("Duplicate", testBufferReader(`{a: 1, 'a': 2}`, func(t *testing.T, d *Decoder) {
+		d.SetRelaxed(true)
+		d.SetDisallowDuplicateKeys(true)
+		var dupErr *DuplicateKeyError
+		require.ErrorAs(t, d.Validate(), &dupErr)
+		require.Equal(t, "a", dupErr.Key)
+	}))
+	t.Run("Reset", func(t *testing.T) {
+		d := GetDecoder()
+		d.SetRelaxed(true)
+		d.ResetBytes([]byte(`[1,]`))
+		require.NoError(t, d.Validate())
+		PutDecoder(d)
+		d.ResetBytes([]byte(`[1,]`))
+		require.Error(t, d.Validate())
+	})
+}
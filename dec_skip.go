@@ -29,16 +29,25 @@ func (d *Decoder) Skip() error {
 		d.unread()
 		return d.skipNumber()
 	case '[':
+		if d.skipIndex() {
+			return nil
+		}
 		if err := d.skipArr(); err != nil {
 			return errors.Wrap(err, "array")
 		}
 		return nil
 	case '{':
+		if d.skipIndex() {
+			return nil
+		}
 		if err := d.skipObj(); err != nil {
 			return errors.Wrap(err, "object")
 		}
 		return nil
 	default:
+		if d.relaxed {
+			return d.skipRelaxed(c)
+		}
 		return badToken(c, d.offset()-1)
 	}
 }
@@ -68,6 +77,20 @@ var skipNumberSet = [256]byte{
 //
 // Assumes d.buf is not empty.
 func (d *Decoder) skipNumber() error {
+	if d.relaxed {
+		return d.skipRelaxedNum()
+	}
+	if d.limits.MaxNumLen <= 0 {
+		return d.skipNum()
+	}
+	start := d.offset()
+	if err := d.skipNum(); err != nil {
+		return err
+	}
+	return d.checkNumLen(d.offset() - start)
+}
+
+func (d *Decoder) skipNum() error {
 	const (
 		digitTag  byte = 1
 		closerTag byte = 2
@@ -265,9 +288,16 @@ var (
 //
 // Assumes first quote was consumed.
 func (d *Decoder) skipStr() error {
+	if d.utf8 == UTF8Strict {
+		// Unread opening quote for str.
+		d.unread()
+		_, err := d.str(value{raw: true})
+		return err
+	}
 	var (
-		c byte
-		i int
+		c     byte
+		i     int
+		start = d.offset()
 	)
 readStr:
 	for {
@@ -332,6 +362,9 @@ readStr:
 			}
 		}
 
+		if err := d.checkStrLen(d.streamOffset + d.tail - start); err != nil {
+			return err
+		}
 		if err := d.read(); err != nil {
 			if err == io.EOF {
 				err = io.ErrUnexpectedEOF
@@ -345,7 +378,7 @@ readTok:
 	switch {
 	case c == '"':
 		d.head += i + 1
-		return nil
+		return d.checkStrLen(d.offset() - 1 - start)
 	case c == '\\':
 		d.head += i + 1
 		v, err := d.byte()
@@ -390,15 +423,20 @@ func (d *Decoder) skipObj() error {
 	case '"':
 		d.unread()
 	default:
-		return badToken(c, d.offset()-1)
+		if !d.relaxed {
+			return badToken(c, d.offset()-1)
+		}
+		// Identifier or single-quoted key.
+		d.unread()
 	}
 
-	for {
-		if err := d.consume('"'); err != nil {
-			return errors.Wrap(err, `'"' expected`)
+	keys := d.pushKeys()
+	for n := 1; ; n++ {
+		if err := d.checkObjKeys(n); err != nil {
+			return err
 		}
-		if err := d.skipStr(); err != nil {
-			return errors.Wrap(err, "read field name")
+		if err := d.skipObjKey(keys); err != nil {
+			return err
 		}
 		if err := d.consume(':'); err != nil {
 			return errors.Wrap(err, `":" expected`)
@@ -412,8 +450,16 @@ func (d *Decoder) skipObj() error {
 		}
 		switch c {
 		case ',':
-			continue
+			if end, err := d.trailingComma('}'); err != nil || !end {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			d.popKeys(keys)
+			return d.decDepth()
 		case '}':
+			d.popKeys(keys)
 			return d.decDepth()
 		default:
 			return badToken(c, d.offset()-1)
@@ -421,6 +467,23 @@ func (d *Decoder) skipObj() error {
 	}
 }
 
+func (d *Decoder) skipObjKey(keys int) error {
+	if keys >= 0 || d.relaxed {
+		// Key is needed to check for duplicates or can be relaxed.
+		if _, err := d.objKey(value{raw: true}, keys); err != nil {
+			return errors.Wrap(err, "read field name")
+		}
+		return nil
+	}
+	if err := d.consume('"'); err != nil {
+		return errors.Wrap(err, `'"' expected`)
+	}
+	if err := d.skipStr(); err != nil {
+		return errors.Wrap(err, "read field name")
+	}
+	return nil
+}
+
 // skipArr reads JSON array.
 //
 // Assumes first bracket was consumed.
@@ -438,7 +501,10 @@ func (d *Decoder) skipArr() error {
 	}
 	d.unread()
 
-	for {
+	for n := 1; ; n++ {
+		if err := d.checkArrElems(n); err != nil {
+			return err
+		}
 		if err := d.Skip(); err != nil {
 			return err
 		}
@@ -448,7 +514,13 @@ func (d *Decoder) skipArr() error {
 		}
 		switch c {
 		case ',':
-			continue
+			if end, err := d.trailingComma(']'); err != nil || !end {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			return d.decDepth()
 		case ']':
 			return d.decDepth()
 		default:
@@ -34,12 +34,31 @@ func (v value) rune(r rune) value {
 }
 
 func (d *Decoder) str(v value) (value, error) {
+	if d.utf8 != UTF8Passthrough {
+		return d.strUTF8(v)
+	}
+	return d.strRaw(v)
+}
+
+// strRaw reads string, passing invalid UTF-8 as-is.
+func (d *Decoder) strRaw(v value) (value, error) {
+	if d.relaxed {
+		c, err := d.more()
+		if err != nil {
+			return value{}, err
+		}
+		if c == '\'' {
+			return d.strSingle(v)
+		}
+		d.unread()
+	}
 	if err := d.consume('"'); err != nil {
 		return value{}, err
 	}
 	var (
-		c byte
-		i int
+		c     byte
+		i     int
+		start = d.offset()
 	)
 	for {
 		buf := d.buf[d.head:d.tail]
@@ -101,7 +120,7 @@ func (d *Decoder) str(v value) (value, error) {
 				goto readTok
 			}
 		}
-		return d.strSlow(v)
+		return d.strSlow(v, start)
 	}
 readTok:
 	buf := d.buf[d.head:d.tail]
@@ -109,6 +128,9 @@ readTok:
 
 	switch {
 	case c == '"':
+		if err := d.checkStrLen(i); err != nil {
+			return value{}, err
+		}
 		// Skip string + last quote.
 		d.head += i + 1
 		if v.raw {
@@ -119,13 +141,14 @@ readTok:
 		// Skip only string, keep quote in buffer.
 		d.head += i
 		// We need a copy anyway, because string is escaped.
-		return d.strSlow(value{buf: append(v.buf, str...)})
+		return d.strSlow(value{buf: append(v.buf, str...)}, start)
 	default:
 		return v, badToken(c, d.offset()+i)
 	}
 }
 
-func (d *Decoder) strSlow(v value) (value, error) {
+// strSlow reads rest of string, start is offset of string literal start.
+func (d *Decoder) strSlow(v value, start int) (value, error) {
 	var (
 		c byte
 		i int
@@ -193,6 +216,9 @@ readStr:
 		}
 
 		v.buf = append(v.buf, d.buf[d.head:d.head+i]...)
+		if err := d.checkStrLen(d.offset() + i - start); err != nil {
+			return value{}, err
+		}
 		if err := d.read(); err != nil {
 			if err == io.EOF {
 				return value{}, io.ErrUnexpectedEOF
@@ -207,6 +233,9 @@ readTok:
 
 	switch {
 	case c == '"':
+		if err := d.checkStrLen(d.offset() - 1 - start); err != nil {
+			return value{}, err
+		}
 		return value{buf: append(v.buf, str...)}, nil
 	case c == '\\':
 		v.buf = append(v.buf, str...)
@@ -260,6 +289,9 @@ func (d *Decoder) escapedChar(v value, c byte) (value, error) {
 			}
 			if c != '\\' {
 				d.unread()
+				if err := d.unpairedSurrogate(r1); err != nil {
+					return value{}, err
+				}
 				return v.rune(r1), nil
 			}
 			c, err = d.byte()
@@ -267,6 +299,9 @@ func (d *Decoder) escapedChar(v value, c byte) (value, error) {
 				return value{}, err
 			}
 			if c != 'u' {
+				if err := d.unpairedSurrogate(r1); err != nil {
+					return value{}, err
+				}
 				return d.escapedChar(v.rune(r1), c)
 			}
 			r2, err := d.readU4()
@@ -275,6 +310,9 @@ func (d *Decoder) escapedChar(v value, c byte) (value, error) {
 			}
 			combined := utf16.DecodeRune(r1, r2)
 			if combined == '\uFFFD' {
+				if err := d.unpairedSurrogate(r1); err != nil {
+					return value{}, err
+				}
 				v = v.rune(r1).rune(r2)
 			} else {
 				v = v.rune(combined)
@@ -0,0 +1,310 @@
+package jx
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/go-faster/errors"
+)
+
+// TokenKind is kind of json token.
+type TokenKind byte
+
+const (
+	// TokenInvalid is zero value of TokenKind.
+	TokenInvalid TokenKind = iota
+	// TokenObjStart is start of object, "{".
+	TokenObjStart
+	// TokenObjEnd is end of object, "}".
+	TokenObjEnd
+	// TokenArrStart is start of array, "[".
+	TokenArrStart
+	// TokenArrEnd is end of array, "]".
+	TokenArrEnd
+	// TokenKey is object key, like "foo".
+	TokenKey
+	// TokenStr is string value, like "foo".
+	TokenStr
+	// TokenNumber is number value, like 100 or 1.01.
+	TokenNumber
+	// TokenBool is boolean value, true or false.
+	TokenBool
+	// TokenNull is null value.
+	TokenNull
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case TokenInvalid:
+		return "invalid"
+	case TokenObjStart:
+		return "object start"
+	case TokenObjEnd:
+		return "object end"
+	case TokenArrStart:
+		return "array start"
+	case TokenArrEnd:
+		return "array end"
+	case TokenKey:
+		return "key"
+	case TokenStr:
+		return "string"
+	case TokenNumber:
+		return "number"
+	case TokenBool:
+		return "bool"
+	case TokenNull:
+		return "null"
+	default:
+		return "unknown"
+	}
+}
+
+// Token is single json token.
+type Token struct {
+	Kind TokenKind
+	// Raw is raw json of token, like `{`, `"foo"` or `10`.
+	//
+	// In relaxed mode, Raw of TokenKey may be an unquoted identifier.
+	Raw Raw
+	// Depth is count of objects and arrays containing token.
+	//
+	// Start and end of container have the same depth as container itself.
+	Depth int
+}
+
+// tokenDelims holds raw json of delimiter tokens.
+var tokenDelims = []byte("{}[]")
+
+type tokenState byte
+
+const (
+	tokenFirst tokenState = iota // after start of container
+	tokenValue                   // after object key
+	tokenNext                    // after value
+)
+
+// tokenFrame is state of object or array being read by Token.
+type tokenFrame struct {
+	// key is buffer for object key, used if Decoder is not buffered.
+	key   []byte
+	n     int // count of elements or keys
+	path  int
+	keys  int
+	obj   bool
+	state tokenState
+}
+
+// Token reads next json token.
+//
+// Returns io.EOF if there are no more top-level values. Other errors are
+// *DecodeError, describing location of failure.
+//
+// Raw of returned token is valid only until next call. Token must not be
+// mixed with other reading methods while inside of object or array.
+func (d *Decoder) Token() (Token, error) {
+	t, err := d.token()
+	if err != nil {
+		if err == io.EOF && len(d.tokens) == 0 {
+			return Token{}, io.EOF
+		}
+		return Token{}, d.decodeErr(err)
+	}
+	return t, nil
+}
+
+func (d *Decoder) token() (Token, error) {
+	n := len(d.tokens)
+	if n == 0 {
+		return d.tokenValue()
+	}
+	f := &d.tokens[n-1]
+	end := byte(']')
+	if f.obj {
+		end = '}'
+	}
+
+	switch f.state {
+	case tokenFirst:
+		c, err := d.more()
+		if err != nil {
+			return Token{}, err
+		}
+		if c == end {
+			return d.tokenEnd()
+		}
+		d.unread()
+	case tokenValue:
+		if err := d.consume(':'); err != nil {
+			return Token{}, errors.Wrap(err, `":" expected`)
+		}
+		f.state = tokenNext
+		return d.tokenValue()
+	default:
+		c, err := d.more()
+		if err != nil {
+			return Token{}, err
+		}
+		switch c {
+		case ',':
+			if closed, err := d.trailingComma(end); err != nil {
+				return Token{}, err
+			} else if closed {
+				return d.tokenEnd()
+			}
+		case end:
+			return d.tokenEnd()
+		default:
+			err := badToken(c, d.offset()-1)
+			return Token{}, errors.Wrapf(err, `"," or %q expected`, end)
+		}
+	}
+
+	f.n++
+	if f.path < 0 {
+		// Path frame is added on first element, as in Obj and Arr.
+		f.path = d.pushPath(f.obj)
+	}
+	if f.obj {
+		if err := d.checkObjKeys(f.n); err != nil {
+			return Token{}, err
+		}
+		t, err := d.tokenKey(f)
+		if err != nil {
+			return Token{}, errors.Wrap(err, "field name")
+		}
+		f.state = tokenValue
+		return t, nil
+	}
+	if err := d.checkArrElems(f.n); err != nil {
+		return Token{}, err
+	}
+	d.path[f.path].index = f.n - 1
+	f.state = tokenNext
+	return d.tokenValue()
+}
+
+// tokenValue reads start of object or array or scalar value.
+func (d *Decoder) tokenValue() (Token, error) {
+	depth := len(d.tokens)
+	c, err := d.next()
+	if err != nil {
+		if err == io.EOF && depth > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return Token{}, err
+	}
+	switch c {
+	case '{', '[':
+		if err := d.incDepth(); err != nil {
+			return Token{}, err
+		}
+		obj := c == '{'
+		if depth < cap(d.tokens) {
+			// Reuse key buffer of previously allocated frame.
+			d.tokens = d.tokens[:depth+1]
+		} else {
+			d.tokens = append(d.tokens, tokenFrame{})
+		}
+		f := &d.tokens[depth]
+		*f = tokenFrame{
+			key:  f.key[:0],
+			path: -1,
+			keys: -1,
+			obj:  obj,
+		}
+		if obj {
+			f.keys = d.pushKeys()
+			return Token{Kind: TokenObjStart, Raw: tokenDelims[0:1], Depth: depth}, nil
+		}
+		return Token{Kind: TokenArrStart, Raw: tokenDelims[2:3], Depth: depth}, nil
+	}
+	d.unread()
+
+	raw, err := d.rawTo(&d.tokenRaw)
+	if err != nil {
+		return Token{}, err
+	}
+	kind := TokenNumber
+	switch c {
+	case '"', '\'':
+		kind = TokenStr
+	case 't', 'f':
+		kind = TokenBool
+	case 'n':
+		kind = TokenNull
+	}
+	return Token{Kind: kind, Raw: raw, Depth: depth}, nil
+}
+
+// tokenEnd pops current frame, assuming that end of container was consumed.
+func (d *Decoder) tokenEnd() (Token, error) {
+	depth := len(d.tokens) - 1
+	f := d.tokens[depth]
+	d.tokens = d.tokens[:depth]
+	if f.path >= 0 {
+		d.popPath(f.path)
+	}
+	d.popKeys(f.keys)
+	if err := d.decDepth(); err != nil {
+		return Token{}, err
+	}
+	if f.obj {
+		return Token{Kind: TokenObjEnd, Raw: tokenDelims[1:2], Depth: depth}, nil
+	}
+	return Token{Kind: TokenArrEnd, Raw: tokenDelims[3:4], Depth: depth}, nil
+}
+
+// tokenKey reads object key of given frame.
+func (d *Decoder) tokenKey(f *tokenFrame) (Token, error) {
+	c, err := d.more()
+	if err != nil {
+		return Token{}, err
+	}
+	d.unread()
+	offset := d.offset()
+	isBuffer := d.reader == nil
+
+	var raw, key []byte
+	switch {
+	case c == '"' || (d.relaxed && c == '\''):
+		if raw, err = d.rawTo(&d.tokenRaw); err != nil {
+			return Token{}, err
+		}
+		if !isBuffer {
+			// Raw references internal buffer that may be overwritten.
+			f.key = append(f.key[:0], raw...)
+			raw = f.key
+		}
+		key = raw[1 : len(raw)-1]
+	case d.relaxed && identSet[c] == identStart:
+		v, err := d.key(value{buf: f.key[:0], raw: isBuffer})
+		if err != nil {
+			return Token{}, err
+		}
+		if !v.raw {
+			f.key = v.buf
+		}
+		raw, key = v.buf, v.buf
+	default:
+		return Token{}, badToken(c, offset)
+	}
+	d.path[f.path].key = key
+
+	if f.keys >= 0 {
+		if bytes.IndexByte(key, '\\') >= 0 {
+			// Decode escaped key to compare.
+			s := d.sub(raw)
+			v, err := s.str(value{buf: d.tokenStr[:0]})
+			if err != nil {
+				return Token{}, err
+			}
+			d.tokenStr = v.buf
+			key = v.buf
+		}
+		if !d.keys[f.keys].add(key) {
+			return Token{}, &DuplicateKeyError{Key: string(key), Offset: offset}
+		}
+	}
+	return Token{Kind: TokenKey, Raw: raw, Depth: len(d.tokens)}, nil
+}
@@ -0,0 +1,244 @@
+package jx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// tokenString reads all tokens from d as "kind@depth:raw" strings.
+func tokenString(d *Decoder) ([]string, error) {
+	var r []string
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return r, nil
+		}
+		if err != nil {
+			return r, err
+		}
+		r = append(r, fmt.Sprintf("%s@%d:%s", t.Kind, t.Depth, t.Raw))
+	}
+}
+
+func TestDecoder_Token(t *testing.T) {
+	const input = `{"a": [1, "x", {"b": null}], "c": {}, "d": [], "e": true} 10`
+	t.Run("Tokens", testBufferReader(input, func(t *testing.T, d *Decoder) {
+		tokens, err := tokenString(d)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			`object start@0:{`,
+			`key@1:"a"`,
+			`array start@1:[`,
+			`number@2:1`,
+			`string@2:"x"`,
+			`object start@2:{`,
+			`key@3:"b"`,
+			`null@3:null`,
+			`object end@2:}`,
+			`array end@1:]`,
+			`key@1:"c"`,
+			`object start@1:{`,
+			`object end@1:}`,
+			`key@1:"d"`,
+			`array start@1:[`,
+			`array end@1:]`,
+			`key@1:"e"`,
+			`bool@1:true`,
+			`object end@0:}`,
+			`number@0:10`,
+		}, tokens)
+	}))
+	t.Run("Relaxed", testBufferReader(`{a: 'b', /* c */ "c": [-Infinity, 0x10,],}`, func(t *testing.T, d *Decoder) {
+		d.SetRelaxed(true)
+		tokens, err := tokenString(d)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			`object start@0:{`,
+			`key@1:a`,
+			`string@1:'b'`,
+			`key@1:"c"`,
+			`array start@1:[`,
+			`number@2:-Infinity`,
+			`number@2:0x10`,
+			`array end@1:]`,
+			`object end@0:}`,
+		}, tokens)
+	}))
+	t.Run("Empty", func(t *testing.T) {
+		_, err := DecodeStr(" ").Token()
+		require.ErrorIs(t, err, io.EOF)
+	})
+}
+
+func TestDecoder_Token_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		Input   string
+		Pointer string
+	}{
+		{`{`, ""},
+		{`{"a"}`, "/a"},
+		{`{"a":}`, "/a"},
+		{`{"a":1,}`, "/a"},
+		{`{1:2}`, "/"},
+		{`[1 2]`, "/0"},
+		{`[1,]`, "/1"},
+		{`[1,2`, "/1"},
+		{`}`, ""},
+		{`[[1],{"b":[x]}]`, "/1/b/0"},
+		{`"unterminated`, ""},
+	} {
+		tt := tt
+		t.Run(tt.Input, testBufferReader(tt.Input, func(t *testing.T, d *Decoder) {
+			_, err := tokenString(d)
+			var decErr *DecodeError
+			require.ErrorAs(t, err, &decErr)
+			require.Equal(t, tt.Pointer, decErr.Pointer())
+		}))
+	}
+	t.Run("DuplicateKey", testBufferReader(`{"a":{"a":1},"a":2}`, func(t *testing.T, d *Decoder) {
+		d.SetDisallowDuplicateKeys(true)
+		_, err := tokenString(d)
+		var dupErr *DuplicateKeyError
+		require.ErrorAs(t, err, &dupErr)
+		require.Equal(t, "a", dupErr.Key)
+		require.Equal(t, 13, dupErr.Offset)
+	}))
+	t.Run("MaxDepth", func(t *testing.T) {
+		d := DecodeStr(`[[[1]]]`)
+		d.SetLimits(Limits{MaxDepth: 2})
+		_, err := tokenString(d)
+		require.ErrorIs(t, err, ErrMaxDepth)
+	})
+	t.Run("MaxArrElems", func(t *testing.T) {
+		d := DecodeStr(`[1,2,3]`)
+		d.SetLimits(Limits{MaxArrElems: 2})
+		_, err := tokenString(d)
+		require.ErrorIs(t, err, ErrMaxArrElems)
+	})
+}
+
+func TestEncoder_Token(t *testing.T) {
+	copyTokens := func(e *Encoder, d *Decoder) error {
+		for {
+			tok, err := d.Token()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if e.Token(tok) {
+				return fmt.Errorf("write %s", tok.Kind)
+			}
+		}
+	}
+	t.Run("Testdata", func(t *testing.T) {
+		runTestdata(t.Fatal, func(name string, data []byte) {
+			t.Run(name, func(t *testing.T) {
+				var expect bytes.Buffer
+				require.NoError(t, json.Compact(&expect, data))
+
+				var e Encoder
+				d := Decode(iotest.HalfReader(bytes.NewReader(data)), 512)
+				require.NoError(t, copyTokens(&e, d))
+				require.Equal(t, expect.String(), e.String())
+			})
+		})
+	})
+	t.Run("Indent", func(t *testing.T) {
+		var e Encoder
+		e.SetIdent(2)
+		require.NoError(t, copyTokens(&e, DecodeStr(`{"a":[1,{"b":null}]}`)))
+		require.Equal(t, "{\n  \"a\": [\n    1,\n    {\n      \"b\": null\n    }\n  ]\n}", e.String())
+	})
+	t.Run("Relaxed", func(t *testing.T) {
+		var e Encoder
+		d := DecodeStr(`{a: 'it\'s', b: ["x",],}`)
+		d.SetRelaxed(true)
+		require.NoError(t, copyTokens(&e, d))
+		require.Equal(t, `{"a":"it's","b":["x"]}`, e.String())
+	})
+	t.Run("RelaxedNum", func(t *testing.T) {
+		var e Encoder
+		d := DecodeStr(`[0x1F, -0xff, 0xFFFFFFFFFFFFFFFFFF, 1.5]`)
+		d.SetRelaxed(true)
+		require.NoError(t, copyTokens(&e, d))
+		require.Equal(t, `[31,-255,4722366482869645213695,1.5]`, e.String())
+
+		for _, lit := range []string{"NaN", "-NaN", "Infinity", "-Infinity"} {
+			var e Encoder
+			e.ArrStart()
+			require.True(t, e.Token(Token{Kind: TokenNumber, Raw: []byte(lit)}), lit)
+			require.Equal(t, `[`, e.String(), lit)
+		}
+	})
+	t.Run("SortKeys", func(t *testing.T) {
+		var e Encoder
+		e.SetSortKeys(true)
+		d := DecodeStr(`{"c": 1, "\u0062": 2, 'a': 3}`)
+		d.SetRelaxed(true)
+		require.NoError(t, copyTokens(&e, d))
+		require.Equal(t, `{"a":3,"\u0062":2,"c":1}`, e.String())
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		var e Encoder
+		require.True(t, e.Token(Token{}))
+		require.True(t, e.Token(Token{Kind: TokenNumber}))
+	})
+}
+
+func TestTokenKind_String(t *testing.T) {
+	for k := TokenInvalid; k <= TokenNull; k++ {
+		require.NotEqual(t, "unknown", k.String())
+	}
+	require.Equal(t, "unknown", TokenKind(255).String())
+}
+
+func BenchmarkDecoder_Token(b *testing.B) {
+	var e Encoder
+	d := GetDecoder()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchData)))
+
+	for i := 0; i < b.N; i++ {
+		e.Reset()
+		d.ResetBytes(benchData)
+		for {
+			tok, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+			e.Token(tok)
+		}
+	}
+}
+
+func ExampleDecoder_Token() {
+	// Replace all numbers with zero.
+	d := DecodeStr(`{"a": [1, 2, {"b": 3}], "c": "d"}`)
+	var e Encoder
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			panic(err)
+		}
+		if tok.Kind == TokenNumber {
+			tok.Raw = Raw("0")
+		}
+		e.Token(tok)
+	}
+	fmt.Println(e)
+	// Output: {"a":[0,0,{"b":0}],"c":"d"}
+}
@@ -0,0 +1,116 @@
+package jx
+
+import (
+	"unicode/utf8"
+
+	"github.com/go-faster/errors"
+)
+
+// ErrInvalidUTF8 means that string contains invalid UTF-8 or unpaired
+// surrogate escape.
+var ErrInvalidUTF8 = errors.New("invalid utf-8")
+
+// UTF8Mode sets how Decoder handles invalid UTF-8 in strings.
+type UTF8Mode byte
+
+// Possible UTF-8 handling modes.
+const (
+	// UTF8Passthrough passes invalid bytes as-is. Default.
+	UTF8Passthrough UTF8Mode = iota
+	// UTF8Strict rejects strings with invalid UTF-8 or unpaired surrogate
+	// escapes with ErrInvalidUTF8, as required by RFC 8259.
+	UTF8Strict
+	// UTF8Replace replaces every invalid byte with U+FFFD, like encoding/json.
+	UTF8Replace
+)
+
+func (m UTF8Mode) String() string {
+	switch m {
+	case UTF8Passthrough:
+		return "passthrough"
+	case UTF8Strict:
+		return "strict"
+	case UTF8Replace:
+		return "replace"
+	default:
+		return "unknown"
+	}
+}
+
+// SetUTF8Mode sets handling of invalid UTF-8 in strings.
+//
+// In UTF8Strict mode, Skip and Validate also check strings.
+// Mode is kept on Reset and ResetBytes, but reset on PutDecoder.
+func (d *Decoder) SetUTF8Mode(m UTF8Mode) {
+	d.utf8 = m
+}
+
+// UTF8Mode returns current UTF-8 handling mode.
+func (d *Decoder) UTF8Mode() UTF8Mode {
+	return d.utf8
+}
+
+// strUTF8 reads string, checking or replacing invalid UTF-8.
+func (d *Decoder) strUTF8(v value) (value, error) {
+	var (
+		start  = d.offset()
+		prefix = len(v.buf)
+	)
+	if v.raw {
+		prefix = 0
+	}
+	v, err := d.strRaw(v)
+	if err != nil {
+		return v, err
+	}
+	s := v.buf[prefix:]
+	if utf8.Valid(s) {
+		return v, nil
+	}
+	if d.utf8 == UTF8Strict {
+		return value{}, errors.Wrapf(ErrInvalidUTF8, "string at %d", start)
+	}
+	fixed := appendValidUTF8(nil, s)
+	if v.raw {
+		// Do not modify input buffer.
+		return value{buf: fixed}, nil
+	}
+	return value{buf: append(v.buf[:prefix], fixed...)}, nil
+}
+
+// appendValidUTF8 appends s to b, replacing every invalid byte with U+FFFD.
+func appendValidUTF8(b, s []byte) []byte {
+	for len(s) > 0 {
+		if s[0] < utf8.RuneSelf {
+			b = append(b, s[0])
+			s = s[1:]
+			continue
+		}
+		r, size := utf8.DecodeRune(s)
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, "�"...)
+		} else {
+			b = append(b, s[:size]...)
+		}
+		s = s[size:]
+	}
+	return b
+}
+
+// unpairedSurrogate returns error if surrogates are rejected.
+func (d *Decoder) unpairedSurrogate(r rune) error {
+	if d.utf8 != UTF8Strict {
+		return nil
+	}
+	return errors.Wrapf(ErrInvalidUTF8, "unpaired surrogate %U", r)
+}
+
+// ValidStrict reports whether data is valid json with strings that are valid
+// UTF-8 without unpaired surrogate escapes.
+func ValidStrict(data []byte) bool {
+	d := GetDecoder()
+	defer PutDecoder(d)
+	d.ResetBytes(data)
+	d.SetUTF8Mode(UTF8Strict)
+	return d.Validate() == nil
+}
@@ -0,0 +1,120 @@
+package jx
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_SetUTF8Mode(t *testing.T) {
+	for i, tt := range []struct {
+		Input   string
+		Valid   bool
+		Replace string
+	}{
+		{`"hello"`, true, "hello"},
+		{`"привет, 世界 🌍"`, true, "привет, 世界 🌍"},
+		{`"\ud83c\udf0d"`, true, "🌍"},
+		{`"\u00e9\n"`, true, "é\n"},
+		{"\"\xff\"", false, "\ufffd"},
+		{"\"a\xc3\"", false, "a\ufffd"},
+		{"\"\xe2\x82\"", false, "\ufffd\ufffd"},
+		{"\"\xed\xa0\x80\"", false, "\ufffd\ufffd\ufffd"},
+		{"\"\\n\xff\\t\"", false, "\n\ufffd\t"},
+		{`"\ud83c"`, false, "\ufffd"},
+		{`"\ud83cx"`, false, "\ufffdx"},
+		{`"\ud83c\n"`, false, "\ufffd\n"},
+		{`"\udf0d"`, false, "\ufffd"},
+		{`"\ud83c\u0041"`, false, "\ufffdA"},
+	} {
+		tt := tt
+		t.Run(fmt.Sprintf("Test%d", i+1), func(t *testing.T) {
+			t.Run("Strict", testBufferReader(tt.Input, func(t *testing.T, d *Decoder) {
+				d.SetUTF8Mode(UTF8Strict)
+				s, err := d.Str()
+				if !tt.Valid {
+					require.ErrorIs(t, err, ErrInvalidUTF8)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tt.Replace, s)
+			}))
+			t.Run("StrictSkip", testBufferReader(tt.Input, func(t *testing.T, d *Decoder) {
+				d.SetUTF8Mode(UTF8Strict)
+				err := d.Skip()
+				if !tt.Valid {
+					require.ErrorIs(t, err, ErrInvalidUTF8)
+					return
+				}
+				require.NoError(t, err)
+			}))
+			t.Run("Replace", testBufferReader(tt.Input, func(t *testing.T, d *Decoder) {
+				d.SetUTF8Mode(UTF8Replace)
+				s, err := d.Str()
+				require.NoError(t, err)
+				require.Equal(t, tt.Replace, s)
+
+				var std string
+				require.NoError(t, json.Unmarshal([]byte(tt.Input), &std))
+				require.Equal(t, std, s, "should match encoding/json")
+			}))
+			t.Run("Passthrough", testBufferReader(tt.Input, func(t *testing.T, d *Decoder) {
+				_, err := d.Str()
+				require.NoError(t, err)
+			}))
+			require.Equal(t, tt.Valid, ValidStrict([]byte(tt.Input)))
+			require.True(t, Valid([]byte(tt.Input)))
+		})
+	}
+}
+
+func TestDecoder_UTF8Append(t *testing.T) {
+	d := DecodeStr("\"b\xffc\"")
+	d.SetUTF8Mode(UTF8Replace)
+	s, err := d.StrAppend([]byte("a\xff"))
+	require.NoError(t, err)
+	require.Equal(t, "a\xffb\ufffdc", string(s), "prefix should be untouched")
+
+	input := []byte("\"\xff\"")
+	d.ResetBytes(input)
+	b, err := d.StrBytes()
+	require.NoError(t, err)
+	require.Equal(t, "\ufffd", string(b))
+	require.Equal(t, "\"\xff\"", string(input), "input should be untouched")
+}
+
+func TestDecoder_UTF8Strict(t *testing.T) {
+	t.Run("Key", testBufferReader("{\"\xff\":1}", func(t *testing.T, d *Decoder) {
+		d.SetUTF8Mode(UTF8Strict)
+		require.ErrorIs(t, d.Obj(func(d *Decoder, key string) error {
+			return d.Skip()
+		}), ErrInvalidUTF8)
+	}))
+	t.Run("Validate", testBufferReader("[1,{\"a\":\"\xff\"}]", func(t *testing.T, d *Decoder) {
+		d.SetUTF8Mode(UTF8Strict)
+		require.ErrorIs(t, d.Validate(), ErrInvalidUTF8)
+	}))
+	t.Run("Reset", func(t *testing.T) {
+		d := GetDecoder()
+		d.SetUTF8Mode(UTF8Strict)
+		d.ResetBytes([]byte("\"\xff\""))
+		require.Equal(t, UTF8Strict, d.UTF8Mode())
+		require.Error(t, d.Validate())
+
+		PutDecoder(d)
+		require.Equal(t, UTF8Passthrough, d.UTF8Mode())
+	})
+}
+
+func TestUTF8Mode_String(t *testing.T) {
+	for m, s := range map[UTF8Mode]string{
+		UTF8Passthrough: "passthrough",
+		UTF8Strict:      "strict",
+		UTF8Replace:     "replace",
+		UTF8Mode(100):   "unknown",
+	} {
+		require.Equal(t, s, m.String())
+	}
+}
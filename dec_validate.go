@@ -8,14 +8,16 @@ import (
 
 // Validate consumes all input, validating that input is a json object
 // without any trialing data.
+//
+// Returned error is *DecodeError, describing location of failure.
 func (d *Decoder) Validate() error {
 	// First encountered value skip should consume all buffer.
 	if err := d.Skip(); err != nil {
-		return errors.Wrap(err, "consume")
+		return d.decodeErr(errors.Wrap(err, "consume"))
 	}
 	// Check for any trialing json.
 	if err := d.Skip(); err != io.EOF {
-		return errors.Wrap(err, "unexpected trialing data")
+		return d.decodeErr(errors.Wrap(err, "unexpected trialing data"))
 	}
 
 	return nil
@@ -0,0 +1,156 @@
+package jx
+
+import (
+	"strconv"
+
+	"github.com/go-faster/errors"
+)
+
+// Difference is difference of two json documents at single location.
+type Difference struct {
+	// Pointer is RFC 6901 JSON Pointer to location.
+	Pointer string
+	// Old is value in first document, nil if value was added.
+	Old Raw
+	// New is value in second document, nil if value was removed.
+	New Raw
+}
+
+// Diff compares json documents a and b.
+//
+// Whitespace and order of object keys are ignored, numbers are compared by
+// value, so 1.0 is equal to 1. Arrays are compared element by element.
+// Differences are reported in order of a, followed by members added in b.
+// Values reference a and b.
+func Diff(a, b Raw) ([]Difference, error) {
+	var df differ
+	if err := df.diff(a, b); err != nil {
+		return nil, errors.Wrap(err, "diff")
+	}
+	return df.diffs, nil
+}
+
+type differ struct {
+	ptr   []byte
+	diffs []Difference
+}
+
+func (df *differ) add(old, new Raw) {
+	df.diffs = append(df.diffs, Difference{
+		Pointer: string(df.ptr),
+		Old:     old,
+		New:     new,
+	})
+}
+
+// at compares a and b at reference token tok.
+func (df *differ) at(tok string, a, b Raw) error {
+	n := len(df.ptr)
+	df.ptr = appendPointerToken(df.ptr, tok)
+	defer func() {
+		df.ptr = df.ptr[:n]
+	}()
+	switch {
+	case a == nil:
+		df.add(nil, b)
+	case b == nil:
+		df.add(a, nil)
+	default:
+		if err := df.diff(a, b); err != nil {
+			return errors.Wrap(err, tok)
+		}
+	}
+	return nil
+}
+
+func (df *differ) diff(a, b Raw) error {
+	ta, tb := a.Type(), b.Type()
+	switch {
+	case ta == Invalid:
+		return errors.Errorf("invalid value %q", a)
+	case tb == Invalid:
+		return errors.Errorf("invalid value %q", b)
+	case ta != tb:
+		if err := DecodeBytes(a).Skip(); err != nil {
+			return err
+		}
+		if err := DecodeBytes(b).Skip(); err != nil {
+			return err
+		}
+		df.add(trimSpace(a), trimSpace(b))
+		return nil
+	}
+	switch ta {
+	case Object:
+		return df.obj(a, b)
+	case Array:
+		return df.arr(a, b)
+	default:
+		eq, err := rawEqual(a, b)
+		if err != nil {
+			return err
+		}
+		if !eq {
+			df.add(trimSpace(a), trimSpace(b))
+		}
+		return nil
+	}
+}
+
+func (df *differ) obj(a, b Raw) error {
+	am, err := rawMembers(DecodeBytes(a), nil)
+	if err != nil {
+		return err
+	}
+	bm, err := rawMembers(DecodeBytes(b), nil)
+	if err != nil {
+		return err
+	}
+
+	// Members of b sorted by key, to find them without map.
+	idx := newMemberIndex(bm)
+	matched := make([]bool, len(bm))
+	for _, m := range am {
+		var v Raw
+		if j := idx.find(m.Key); j >= 0 {
+			matched[j] = true
+			v = bm[j].Value
+		}
+		if err := df.at(m.Key, m.Value, v); err != nil {
+			return err
+		}
+	}
+	for j, m := range bm {
+		if matched[j] || idx.find(m.Key) != j {
+			continue
+		}
+		if err := df.at(m.Key, nil, m.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (df *differ) arr(a, b Raw) error {
+	ae, err := rawElems(DecodeBytes(a), nil)
+	if err != nil {
+		return err
+	}
+	be, err := rawElems(DecodeBytes(b), nil)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(ae) || i < len(be); i++ {
+		var x, y Raw
+		if i < len(ae) {
+			x = ae[i]
+		}
+		if i < len(be) {
+			y = be[i]
+		}
+		if err := df.at(strconv.Itoa(i), x, y); err != nil {
+			return err
+		}
+	}
+	return nil
+}
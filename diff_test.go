@@ -0,0 +1,104 @@
+package jx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	for _, tt := range []struct {
+		Name   string
+		A, B   string
+		Expect []Difference
+	}{
+		{
+			Name: "Equal",
+			A:    `{"a": [1, 2.0, {"b": null}], "c": "d", "e": 1e2}`,
+			B:    `{"c":"d","e":100,"a":[1.0,2,{"b":null}]}`,
+		},
+		{
+			Name:   "Scalar",
+			A:      ` 1 `,
+			B:      `"1"`,
+			Expect: []Difference{{Pointer: "", Old: Raw("1"), New: Raw(`"1"`)}},
+		},
+		{
+			Name: "Object",
+			A:    `{"a":1,"b":{"c":true,"d":[]},"e/f":null}`,
+			B:    `{"g":"new","b":{"c":false,"d":[]},"a":1.0}`,
+			Expect: []Difference{
+				{Pointer: "/b/c", Old: Raw("true"), New: Raw("false")},
+				{Pointer: "/e~1f", Old: Raw("null")},
+				{Pointer: "/g", New: Raw(`"new"`)},
+			},
+		},
+		{
+			Name: "Array",
+			A:    `[1,[2,3],{"a":1}]`,
+			B:    `[1,[2],{"a":2},4,5]`,
+			Expect: []Difference{
+				{Pointer: "/1/1", Old: Raw("3")},
+				{Pointer: "/2/a", Old: Raw("1"), New: Raw("2")},
+				{Pointer: "/3", New: Raw("4")},
+				{Pointer: "/4", New: Raw("5")},
+			},
+		},
+		{
+			Name: "Type",
+			A:    `{"a":{"b":1}}`,
+			B:    `{"a":[1]}`,
+			Expect: []Difference{
+				{Pointer: "/a", Old: Raw(`{"b":1}`), New: Raw("[1]")},
+			},
+		},
+		{
+			Name: "Escaped",
+			A:    `{"a":"é"}`,
+			B:    `{"a":"é"}`,
+		},
+		{
+			Name: "Duplicate",
+			A:    `{"a":1}`,
+			B:    `{"a":2,"a":1}`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.Name, func(t *testing.T) {
+			diffs, err := Diff(Raw(tt.A), Raw(tt.B))
+			require.NoError(t, err)
+			require.Equal(t, tt.Expect, diffs)
+		})
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		for _, tt := range []struct {
+			A, B string
+		}{
+			{``, `1`},
+			{`1`, `{`},
+			{`{"a":1}`, `{"a":}`},
+			{`[1,2]`, `[1,]`},
+		} {
+			_, err := Diff(Raw(tt.A), Raw(tt.B))
+			require.Error(t, err, "%s %s", tt.A, tt.B)
+		}
+	})
+}
+
+func BenchmarkDiff(b *testing.B) {
+	data := benchData
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		diffs, err := Diff(data, data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(diffs) != 0 {
+			b.Fatal(diffs)
+		}
+	}
+}
@@ -23,6 +23,26 @@ type Encoder struct {
 	//
 	// See https://yourbasic.org/algorithms/your-basic-int/#simple-sets
 	first []bool
+
+	// Object fields sorting state, see SetSortKeys.
+	sortKeys   bool
+	keyLess    func(a, b []byte) bool
+	sortStack  []sortFrame
+	sortFields []sortField
+	sortKeyBuf []byte
+	sortKeyStr []byte // unescaped key of token
+	sortBuf    []byte
+	sorter     fieldsByKey
+	// sortStream is stream of underlying writer, held while writing
+	// sorted object, and sortCap is capacity of buffer before holding.
+	sortStream *streamState
+	sortCap    int
+
+	// Checked mode state, see SetChecked.
+	checked    bool
+	checkStack []checkFrame
+	checkTop   bool // top-level value is written
+	checkError error
 }
 
 // Write implements io.Writer.
@@ -49,14 +69,18 @@ func (e Encoder) String() string {
 //
 // If e is in streaming mode, it is reset to non-streaming mode.
 func (e *Encoder) Reset() {
+	e.sortReset()
 	e.w.Reset()
 	e.first = e.first[:0]
+	e.checkReset()
 }
 
 // ResetWriter resets underlying buffer and sets output writer.
 func (e *Encoder) ResetWriter(out io.Writer) {
+	e.sortReset()
 	e.w.ResetWriter(out)
 	e.first = e.first[:0]
+	e.checkReset()
 }
 
 // Grow grows the underlying buffer
@@ -103,8 +127,13 @@ func (e *Encoder) Bool(v bool) bool {
 //
 // Use Obj as convenience helper for writing objects.
 func (e *Encoder) ObjStart() (fail bool) {
-	fail = e.comma() || e.w.ObjStart()
+	if e.checked && e.checkValue() {
+		return true
+	}
+	e.sortObjStart()
+	fail = e.separator() || e.w.ObjStart()
 	e.begin()
+	e.checkBegin(true)
 	return fail || e.writeIndent()
 }
 
@@ -114,7 +143,13 @@ func (e *Encoder) ObjStart() (fail bool) {
 //
 // Use Field as convenience helper for encoding fields.
 func (e *Encoder) FieldStart(field string) (fail bool) {
-	fail = e.comma() || e.w.FieldStart(field)
+	if e.checked && e.checkKey() {
+		return true
+	}
+	sep := len(e.w.Buf)
+	fail = e.separator()
+	addSortField(e, sep, field)
+	fail = fail || e.w.FieldStart(field)
 	if e.indent > 0 {
 		fail = fail || e.byte(' ')
 	}
@@ -138,7 +173,15 @@ func (e *Encoder) Field(name string, f func(e *Encoder)) (fail bool) {
 //
 // Use Obj as convenience helper for writing objects.
 func (e *Encoder) ObjEnd() bool {
+	if e.checkEnd(true) {
+		e.end()
+		return true
+	}
+	fail := e.sortObjEnd()
 	e.end()
+	if fail {
+		return true
+	}
 	return e.writeIndent() || e.w.ObjEnd()
 }
 
@@ -166,8 +209,12 @@ func (e *Encoder) Obj(f func(e *Encoder)) (fail bool) {
 //
 // Use Arr as convenience helper for writing arrays.
 func (e *Encoder) ArrStart() (fail bool) {
-	fail = e.comma() || e.w.ArrStart()
+	if e.checked && e.checkValue() {
+		return true
+	}
+	fail = e.separator() || e.w.ArrStart()
 	e.begin()
+	e.checkBegin(false)
 	return fail || e.writeIndent()
 }
 
@@ -182,6 +229,10 @@ func (e *Encoder) ArrEmpty() bool {
 //
 // Use Arr as convenience helper for writing arrays.
 func (e *Encoder) ArrEnd() bool {
+	if e.checkEnd(false) {
+		e.end()
+		return true
+	}
 	e.end()
 	return e.writeIndent() ||
 		e.w.ArrEnd()
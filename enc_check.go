@@ -0,0 +1,124 @@
+package jx
+
+import "github.com/go-faster/errors"
+
+// SetChecked enables checked mode, in which Encoder tracks kind of
+// containers being written and reports misuse that leads to invalid json:
+//
+//   - value in object without FieldStart
+//   - FieldStart outside of object or without value
+//   - ObjEnd or ArrEnd that does not match start
+//   - unclosed objects or arrays on Close
+//   - more than one top-level value
+//
+// Method that detects misuse writes nothing and returns true, the error is
+// recorded and returned by Err and Close. Useful in tests and debug builds.
+//
+// Checked mode is kept on Reset, but reset on PutEncoder.
+func (e *Encoder) SetChecked(v bool) {
+	e.checked = v
+}
+
+var (
+	errValueWithoutKey = errors.New("value without field name")
+	errKeyOutsideObj   = errors.New("field name outside of object")
+	errKeyWithoutValue = errors.New("field name without value")
+	errUnexpectedEnd   = errors.New("unexpected end of object or array")
+	errUnclosed        = errors.New("unclosed object or array")
+	errMultipleValues  = errors.New("multiple top-level values")
+)
+
+// checkFrame is object or array being written in checked mode.
+type checkFrame struct {
+	obj bool
+	key bool // field name is written, value is expected
+}
+
+// checkErr records misuse error, returning true.
+func (e *Encoder) checkErr(err error, op string) bool {
+	if e.checkError == nil {
+		e.checkError = errors.Wrap(err, op)
+	}
+	return true
+}
+
+// checkValue reports misuse if value can't be written.
+func (e *Encoder) checkValue() bool {
+	n := len(e.checkStack)
+	if n == 0 {
+		if e.checkTop {
+			return e.checkErr(errMultipleValues, "value")
+		}
+		e.checkTop = true
+		return false
+	}
+	f := &e.checkStack[n-1]
+	if f.obj {
+		if !f.key {
+			return e.checkErr(errValueWithoutKey, "value")
+		}
+		f.key = false
+	}
+	return false
+}
+
+// checkKey reports misuse if field name can't be written.
+func (e *Encoder) checkKey() bool {
+	n := len(e.checkStack)
+	if n == 0 || !e.checkStack[n-1].obj {
+		return e.checkErr(errKeyOutsideObj, "field")
+	}
+	f := &e.checkStack[n-1]
+	if f.key {
+		return e.checkErr(errKeyWithoutValue, "field")
+	}
+	f.key = true
+	return false
+}
+
+// checkBegin should be called after start of object or array is written.
+func (e *Encoder) checkBegin(obj bool) {
+	if !e.checked {
+		return
+	}
+	e.checkStack = append(e.checkStack, checkFrame{obj: obj})
+}
+
+// checkEnd reports misuse if end of object or array can't be written.
+//
+// Frame is removed anyway to keep state consistent with first.
+func (e *Encoder) checkEnd(obj bool) bool {
+	if !e.checked {
+		return false
+	}
+	n := len(e.checkStack)
+	if n == 0 {
+		return e.checkErr(errUnexpectedEnd, "end")
+	}
+	f := e.checkStack[n-1]
+	e.checkStack = e.checkStack[:n-1]
+	switch {
+	case f.obj != obj:
+		return e.checkErr(errUnexpectedEnd, "end")
+	case f.key:
+		return e.checkErr(errKeyWithoutValue, "end")
+	}
+	return false
+}
+
+func (e *Encoder) checkReset() {
+	e.checkStack = e.checkStack[:0]
+	e.checkTop = false
+	e.checkError = nil
+}
+
+// checkClose returns error of checked mode, if any.
+func (e *Encoder) checkClose() error {
+	if e.checkError != nil {
+		return e.checkError
+	}
+	if len(e.checkStack) > 0 {
+		return errUnclosed
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package jx
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder_SetChecked(t *testing.T) {
+	for _, tt := range []struct {
+		Name   string
+		Encode func(e *Encoder) bool
+		Expect string
+		Err    error
+	}{
+		{
+			Name: "ValueWithoutKey",
+			Encode: func(e *Encoder) bool {
+				e.ObjStart()
+				return e.Str("a")
+			},
+			Expect: `{`,
+			Err:    errValueWithoutKey,
+		},
+		{
+			Name: "KeyOutsideObj",
+			Encode: func(e *Encoder) bool {
+				e.ArrStart()
+				return e.FieldStart("a")
+			},
+			Expect: `[`,
+			Err:    errKeyOutsideObj,
+		},
+		{
+			Name: "KeyTopLevel",
+			Encode: func(e *Encoder) bool {
+				return e.FieldStart("a")
+			},
+			Err: errKeyOutsideObj,
+		},
+		{
+			Name: "KeyWithoutValue",
+			Encode: func(e *Encoder) bool {
+				e.ObjStart()
+				e.FieldStart("a")
+				return e.FieldStart("b")
+			},
+			Expect: `{"a":`,
+			Err:    errKeyWithoutValue,
+		},
+		{
+			Name: "EndWithoutValue",
+			Encode: func(e *Encoder) bool {
+				e.ObjStart()
+				e.FieldStart("a")
+				return e.ObjEnd()
+			},
+			Expect: `{"a":`,
+			Err:    errKeyWithoutValue,
+		},
+		{
+			Name: "ObjEndOnArray",
+			Encode: func(e *Encoder) bool {
+				e.ArrStart()
+				e.Int(1)
+				return e.ObjEnd()
+			},
+			Expect: `[1`,
+			Err:    errUnexpectedEnd,
+		},
+		{
+			Name: "ArrEndOnObject",
+			Encode: func(e *Encoder) bool {
+				e.ObjStart()
+				return e.ArrEnd()
+			},
+			Expect: `{`,
+			Err:    errUnexpectedEnd,
+		},
+		{
+			Name: "NestedStartWithoutKey",
+			Encode: func(e *Encoder) bool {
+				// Nothing is written, so ends should not match.
+				e.ObjStart()
+				e.ObjStart()
+				e.ObjEnd()
+				return e.ObjEnd()
+			},
+			Expect: `{}`,
+			Err:    errValueWithoutKey,
+		},
+		{
+			Name: "ArrStartWithoutKey",
+			Encode: func(e *Encoder) bool {
+				e.ObjStart()
+				e.ArrStart()
+				return e.ArrEnd()
+			},
+			Expect: `{`,
+			Err:    errValueWithoutKey,
+		},
+		{
+			Name: "MultipleValues",
+			Encode: func(e *Encoder) bool {
+				e.Int(1)
+				return e.Int(2)
+			},
+			Expect: `1`,
+			Err:    errMultipleValues,
+		},
+		{
+			Name: "MultipleObjects",
+			Encode: func(e *Encoder) bool {
+				e.ObjEmpty()
+				return e.ObjStart()
+			},
+			Expect: `{}`,
+			Err:    errMultipleValues,
+		},
+		{
+			Name: "EndTopLevel",
+			Encode: func(e *Encoder) bool {
+				e.ArrEmpty()
+				return e.ArrEnd()
+			},
+			Expect: `[]`,
+			Err:    errUnexpectedEnd,
+		},
+	} {
+		tt := tt
+		t.Run(tt.Name, func(t *testing.T) {
+			var e Encoder
+			e.SetChecked(true)
+			require.True(t, tt.Encode(&e))
+			require.Equal(t, tt.Expect, e.String())
+			require.ErrorIs(t, e.Err(), tt.Err)
+			require.ErrorIs(t, e.Close(), tt.Err)
+
+			// First error is kept.
+			e.Null()
+			require.ErrorIs(t, e.Err(), tt.Err)
+
+			e.Reset()
+			require.NoError(t, e.Err())
+		})
+	}
+}
+
+func TestEncoder_SetChecked_Valid(t *testing.T) {
+	var e Encoder
+	e.SetChecked(true)
+	encodeUnsorted(&e)
+	require.NoError(t, e.Close())
+
+	e.Reset()
+	e.Int(10)
+	require.NoError(t, e.Close())
+
+	e.Reset()
+	e.Obj(func(e *Encoder) {
+		e.FieldStart("a")
+		e.Obj(func(e *Encoder) {
+			e.FieldStart("b")
+			e.ArrEmpty()
+		})
+		e.FieldStart("c")
+		e.Arr(func(e *Encoder) {
+			e.ObjEmpty()
+			e.Raw([]byte(`{"x":1}`))
+		})
+	})
+	require.NoError(t, e.Err())
+	require.NoError(t, e.Close())
+
+	t.Run("Token", func(t *testing.T) {
+		var e Encoder
+		e.SetChecked(true)
+		d := DecodeStr(`{"a":[1,{"b":null}],"c":{}}`)
+		for {
+			tok, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			require.False(t, e.Token(tok))
+		}
+		require.NoError(t, e.Close())
+	})
+}
+
+func TestEncoder_SetChecked_Close(t *testing.T) {
+	var e Encoder
+	e.SetChecked(true)
+	e.ObjStart()
+	e.FieldStart("a")
+	e.ArrStart()
+	require.NoError(t, e.Err())
+	require.ErrorIs(t, e.Close(), errUnclosed)
+
+	// Not checked.
+	e.SetChecked(false)
+	require.NoError(t, e.Close())
+}
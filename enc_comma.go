@@ -17,6 +17,13 @@ func (e *Encoder) current() int { return len(e.first) - 1 }
 
 // comma should be called before any new value.
 func (e *Encoder) comma() bool {
+	if e.checked && e.checkValue() {
+		return true
+	}
+	return e.separator()
+}
+
+func (e *Encoder) separator() bool {
 	// Writing commas.
 	// 1. Before every field expect first.
 	// 2. Before every array element except first.
@@ -0,0 +1,80 @@
+package jx
+
+import (
+	"io"
+
+	"github.com/go-faster/errors"
+)
+
+// LineWriter writes newline-delimited json (NDJSON, JSON Lines), one compact
+// value per line.
+type LineWriter struct {
+	e Encoder
+	// line is checked encoder of value written by Encode.
+	line Encoder
+}
+
+// NewLineWriter creates new LineWriter that writes to w using streaming
+// Encoder with given buffer size.
+//
+// Call Flush or Close to write buffered lines.
+func NewLineWriter(w io.Writer, bufSize int) *LineWriter {
+	l := &LineWriter{e: *NewStreamingEncoder(w, bufSize)}
+	l.line.SetChecked(true)
+	return l
+}
+
+var errNoValue = errors.New("no value")
+
+// Encode calls f to encode single value and writes it as a line.
+//
+// Value is encoded in checked mode, see Encoder.SetChecked, and nothing is
+// written if f does not encode exactly one valid value.
+func (w *LineWriter) Encode(f func(e *Encoder)) error {
+	line := &w.line
+	line.Reset()
+	f(line)
+	if err := line.checkClose(); err != nil {
+		return err
+	}
+	if !line.checkTop {
+		return errNoValue
+	}
+	if w.e.w.Raw(line.w.Buf) {
+		return w.e.Err()
+	}
+	return w.endLine()
+}
+
+// Raw writes raw json value as a line, removing insignificant whitespace.
+//
+// Returns error if v is not a single valid json value.
+func (w *LineWriter) Raw(v Raw) error {
+	if !Valid(v) {
+		return errors.New("invalid json")
+	}
+	if err := DecodeBytes(v).Compact(&w.e.w); err != nil {
+		return err
+	}
+	return w.endLine()
+}
+
+func (w *LineWriter) endLine() error {
+	if w.e.byte('\n') {
+		return w.e.Err()
+	}
+	return nil
+}
+
+// Flush writes buffered lines to underlying writer.
+func (w *LineWriter) Flush() error {
+	if w.e.w.Flush() {
+		return w.e.Err()
+	}
+	return nil
+}
+
+// Close flushes buffered lines to underlying writer.
+func (w *LineWriter) Close() error {
+	return w.e.Close()
+}
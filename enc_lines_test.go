@@ -0,0 +1,70 @@
+package jx
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := NewLineWriter(&out, 32)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, w.Encode(func(e *Encoder) {
+			e.Obj(func(e *Encoder) {
+				e.Field("id", func(e *Encoder) { e.Int(i) })
+				e.Field("tags", func(e *Encoder) {
+					e.Arr(func(e *Encoder) {
+						e.Str("a")
+						e.Str("b")
+					})
+				})
+			})
+		}))
+	}
+	require.NoError(t, w.Raw(Raw("{\n  \"raw\": \"a b\\\" c\",\n  \"v\": [1, 2]\n}")))
+	require.NoError(t, w.Close())
+	require.Equal(t, `{"id":0,"tags":["a","b"]}
+{"id":1,"tags":["a","b"]}
+{"id":2,"tags":["a","b"]}
+{"raw":"a b\" c","v":[1,2]}
+`, out.String())
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		r := NewLineReader(&out)
+		var n int
+		for r.Next() {
+			require.True(t, Valid(r.Raw()))
+			n++
+		}
+		require.NoError(t, r.Err())
+		require.Equal(t, 4, n)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		var out bytes.Buffer
+		w := NewLineWriter(&out, 0)
+		require.Error(t, w.Encode(func(e *Encoder) { e.ArrStart() }))
+		require.ErrorIs(t, w.Encode(func(e *Encoder) {}), errNoValue)
+		require.ErrorIs(t, w.Encode(func(e *Encoder) {
+			e.Int(1)
+			e.Int(2)
+		}), errMultipleValues)
+		require.ErrorIs(t, w.Encode(func(e *Encoder) {
+			e.ObjStart()
+			e.Int(1)
+			e.ObjEnd()
+		}), errValueWithoutKey)
+		require.Error(t, w.Raw(Raw(`{"a":1} {}`)))
+		require.NoError(t, w.Encode(func(e *Encoder) { e.Null() }))
+		require.NoError(t, w.Close())
+		require.Equal(t, "null\n", out.String())
+
+		w = NewLineWriter(&errWriter{err: io.ErrClosedPipe}, 0)
+		require.NoError(t, w.Encode(func(e *Encoder) { e.Null() }))
+		require.ErrorIs(t, w.Flush(), io.ErrClosedPipe)
+		require.ErrorIs(t, w.Encode(func(e *Encoder) { e.Null() }), io.ErrClosedPipe)
+		require.ErrorIs(t, w.Close(), io.ErrClosedPipe)
+	})
+}
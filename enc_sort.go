@@ -0,0 +1,186 @@
+package jx
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/go-faster/jx/internal/byteseq"
+)
+
+// SetSortKeys enables writing object fields sorted by key.
+//
+// Fields of every object are buffered until ObjEnd and then reordered,
+// so output does not depend on order of FieldStart calls. In streaming
+// mode, buffer is not flushed until end of outermost object.
+//
+// Keys are compared bytewise, use SetKeyLess for custom order. Fields with
+// equal keys keep their relative order.
+func (e *Encoder) SetSortKeys(v bool) {
+	e.sortKeys = v
+}
+
+// SetKeyLess sets function that reports whether key a must be written
+// before key b if sorting is enabled by SetSortKeys.
+//
+// If less is nil, keys are compared bytewise.
+func (e *Encoder) SetKeyLess(less func(a, b []byte) bool) {
+	e.keyLess = less
+}
+
+// sortFrame is object being sorted.
+type sortFrame struct {
+	fields int // index of first field in Encoder.sortFields
+	keys   int // start of keys in Encoder.sortKeyBuf
+}
+
+// sortField is span of object field in buffer.
+type sortField struct {
+	// Span of separator and field in Encoder.w.Buf or, after copy in
+	// sortObjEnd, span of field in Encoder.sortBuf.
+	sep   int
+	start int
+	// key is span of key in Encoder.sortKeyBuf.
+	keyStart int
+	keyEnd   int
+}
+
+// sortObjStart should be called before writing object start.
+func (e *Encoder) sortObjStart() {
+	if !e.sortKeys {
+		return
+	}
+	if len(e.sortStack) == 0 && e.w.stream != nil {
+		// Hold streaming until end of outermost object, otherwise fields
+		// may be flushed before reordering.
+		e.sortStream = e.w.stream
+		e.sortCap = cap(e.w.Buf)
+		e.w.stream = nil
+	}
+	e.sortStack = append(e.sortStack, sortFrame{
+		fields: len(e.sortFields),
+		keys:   len(e.sortKeyBuf),
+	})
+}
+
+// addSortField should be called before writing object field, sep is start of
+// separator written by comma.
+func addSortField[S byteseq.Byteseq](e *Encoder, sep int, key S) {
+	if len(e.sortStack) == 0 {
+		return
+	}
+	f := sortField{
+		sep:      sep,
+		start:    len(e.w.Buf),
+		keyStart: len(e.sortKeyBuf),
+	}
+	e.sortKeyBuf = append(e.sortKeyBuf, key...)
+	f.keyEnd = len(e.sortKeyBuf)
+	e.sortFields = append(e.sortFields, f)
+}
+
+// addSortToken is addSortField for raw key of token, key is unescaped to
+// be compared like one written by FieldStart.
+func (e *Encoder) addSortToken(sep int, raw []byte) {
+	if len(e.sortStack) == 0 {
+		return
+	}
+	key := raw
+	if len(raw) > 1 && (raw[0] == '"' || raw[0] == '\'') {
+		key = raw[1 : len(raw)-1]
+		if bytes.IndexByte(key, '\\') >= 0 {
+			d := Decoder{buf: raw, tail: len(raw), relaxed: raw[0] == '\''}
+			if v, err := d.str(value{buf: e.sortKeyStr[:0]}); err == nil {
+				e.sortKeyStr = v.buf
+				key = v.buf
+			}
+		}
+	}
+	addSortField(e, sep, key)
+}
+
+// sortObjEnd reorders fields of current object, should be called before
+// writing object end.
+func (e *Encoder) sortObjEnd() (fail bool) {
+	if len(e.sortStack) == 0 {
+		return false
+	}
+	frame := e.sortStack[len(e.sortStack)-1]
+	e.sortStack = e.sortStack[:len(e.sortStack)-1]
+
+	fields := e.sortFields[frame.fields:]
+	if len(fields) > 1 {
+		// Copy fields without separators.
+		var (
+			first = fields[0].start
+			buf   = e.sortBuf[:0]
+		)
+		for i, f := range fields {
+			end := len(e.w.Buf)
+			if i+1 < len(fields) {
+				end = fields[i+1].sep
+			}
+			start := len(buf)
+			buf = append(buf, e.w.Buf[f.start:end]...)
+			fields[i].sep, fields[i].start = start, len(buf)
+		}
+		e.sortBuf = buf
+
+		// Use pointer to field to prevent allocation.
+		e.sorter = fieldsByKey{e: e, fields: fields}
+		sort.Stable(&e.sorter)
+		e.sorter = fieldsByKey{}
+		e.w.Buf = e.w.Buf[:first]
+		for i, f := range fields {
+			if i > 0 {
+				fail = fail || e.byte(',') || e.writeIndent()
+			}
+			fail = fail || e.w.Raw(buf[f.sep:f.start])
+		}
+	}
+	e.sortKeyBuf = e.sortKeyBuf[:frame.keys]
+	e.sortFields = e.sortFields[:frame.fields]
+
+	if len(e.sortStack) == 0 && e.sortStream != nil {
+		e.w.stream, e.sortStream = e.sortStream, nil
+		if len(e.w.Buf) >= e.sortCap {
+			fail = fail || e.w.Flush()
+		}
+	}
+	return fail
+}
+
+// sortReset resets sorting state, restoring held stream. Should be called
+// before reset of writer.
+func (e *Encoder) sortReset() {
+	e.sortStack = e.sortStack[:0]
+	e.sortFields = e.sortFields[:0]
+	e.sortKeyBuf = e.sortKeyBuf[:0]
+	if e.sortStream != nil {
+		e.w.stream, e.sortStream = e.sortStream, nil
+	}
+}
+
+// fieldsByKey sorts fields by key.
+type fieldsByKey struct {
+	e      *Encoder
+	fields []sortField
+}
+
+func (s fieldsByKey) Len() int { return len(s.fields) }
+
+func (s fieldsByKey) Swap(i, j int) {
+	s.fields[i], s.fields[j] = s.fields[j], s.fields[i]
+}
+
+func (s fieldsByKey) Less(i, j int) bool {
+	var (
+		buf  = s.e.sortKeyBuf
+		a, b = s.fields[i], s.fields[j]
+		ka   = buf[a.keyStart:a.keyEnd]
+		kb   = buf[b.keyStart:b.keyEnd]
+	)
+	if less := s.e.keyLess; less != nil {
+		return less(ka, kb)
+	}
+	return bytes.Compare(ka, kb) < 0
+}
@@ -0,0 +1,184 @@
+package jx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encodeUnsorted(e *Encoder) {
+	e.Obj(func(e *Encoder) {
+		e.Field("c", func(e *Encoder) {
+			e.Arr(func(e *Encoder) {
+				e.Obj(func(e *Encoder) {
+					e.Field("y", func(e *Encoder) { e.Int(1) })
+					e.Field("x", func(e *Encoder) { e.Int(2) })
+				})
+				e.Int(3)
+			})
+		})
+		e.Field("a", func(e *Encoder) { e.Null() })
+		e.Field("b", func(e *Encoder) {
+			e.Obj(func(e *Encoder) {
+				e.Field("z", func(e *Encoder) { e.Str("z") })
+			})
+		})
+		e.FieldStart("e")
+		e.ObjEmpty()
+		e.FieldStart("d")
+		e.ArrEmpty()
+	})
+}
+
+func TestEncoder_SetSortKeys(t *testing.T) {
+	t.Run("Compact", func(t *testing.T) {
+		var e Encoder
+		e.SetSortKeys(true)
+		encodeUnsorted(&e)
+		require.Equal(t, `{"a":null,"b":{"z":"z"},"c":[{"x":2,"y":1},3],"d":[],"e":{}}`, e.String())
+	})
+	t.Run("Indent", func(t *testing.T) {
+		var e Encoder
+		e.SetSortKeys(true)
+		e.SetIdent(1)
+		encodeUnsorted(&e)
+		require.Equal(t, `{
+ "a": null,
+ "b": {
+  "z": "z"
+ },
+ "c": [
+  {
+   "x": 2,
+   "y": 1
+  },
+  3
+ ],
+ "d": [],
+ "e": {}
+}`, e.String())
+	})
+	t.Run("KeyLess", func(t *testing.T) {
+		var e Encoder
+		e.SetSortKeys(true)
+		e.SetKeyLess(func(a, b []byte) bool {
+			return bytes.Compare(a, b) > 0
+		})
+		encodeUnsorted(&e)
+		require.Equal(t, `{"e":{},"d":[],"c":[{"y":1,"x":2},3],"b":{"z":"z"},"a":null}`, e.String())
+	})
+	t.Run("Stable", func(t *testing.T) {
+		var e Encoder
+		e.SetSortKeys(true)
+		e.Obj(func(e *Encoder) {
+			for i, k := range []string{"b", "a", "b", "a"} {
+				e.Field(k, func(e *Encoder) { e.Int(i) })
+			}
+		})
+		require.Equal(t, `{"a":1,"a":3,"b":0,"b":2}`, e.String())
+	})
+	t.Run("Escaped", func(t *testing.T) {
+		var e Encoder
+		e.SetSortKeys(true)
+		e.Obj(func(e *Encoder) {
+			e.Field("b\n", func(e *Encoder) { e.Int(1) })
+			e.Field("b", func(e *Encoder) { e.Int(2) })
+		})
+		require.Equal(t, `{"b":2,"b\n":1}`, e.String())
+	})
+	t.Run("Token", func(t *testing.T) {
+		var e Encoder
+		e.SetSortKeys(true)
+		d := DecodeStr(`{"b":{"d":1,"c":2},"a":[{"f":3,"e":4}]}`)
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				break
+			}
+			e.Token(tok)
+		}
+		require.Equal(t, `{"a":[{"e":4,"f":3}],"b":{"c":2,"d":1}}`, e.String())
+	})
+	t.Run("Reset", func(t *testing.T) {
+		var e Encoder
+		e.SetSortKeys(true)
+		e.ObjStart()
+		e.FieldStart("b")
+		e.Reset()
+		encodeUnsorted(&e)
+		require.Equal(t, `{"a":null,"b":{"z":"z"},"c":[{"x":2,"y":1},3],"d":[],"e":{}}`, e.String())
+	})
+}
+
+func TestEncoder_SetSortKeys_Streaming(t *testing.T) {
+	encode := func(e *Encoder) {
+		e.Arr(func(e *Encoder) {
+			for i := 0; i < 3; i++ {
+				e.Obj(func(e *Encoder) {
+					for j := 10; j > 0; j-- {
+						e.Field(fmt.Sprintf("key_%02d_%s", j, strings.Repeat("x", 10)), func(e *Encoder) {
+							e.Int(j)
+						})
+					}
+				})
+			}
+		})
+	}
+	var expected Encoder
+	expected.SetSortKeys(true)
+	encode(&expected)
+
+	var got bytes.Buffer
+	e := NewStreamingEncoder(&got, minEncoderBufSize)
+	e.SetSortKeys(true)
+	encode(e)
+	require.NoError(t, e.Close())
+	require.Equal(t, expected.String(), got.String())
+	require.True(t, strings.HasPrefix(got.String(), `[{"key_01_`))
+
+	t.Run("ResetWriter", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		e := NewStreamingEncoder(io.Discard, minEncoderBufSize)
+		e.SetSortKeys(true)
+		e.SetContext(ctx)
+		e.ObjStart()
+		e.FieldStart("a")
+
+		var got bytes.Buffer
+		e.ResetWriter(&got)
+		encode(e)
+		require.NoError(t, e.Close())
+		require.Equal(t, expected.String(), got.String())
+
+		// Context of held stream is kept.
+		cancel()
+		e.ObjStart()
+		e.ResetWriter(&got)
+		e.Null()
+		require.ErrorIs(t, e.Close(), context.Canceled)
+	})
+	t.Run("Reset", func(t *testing.T) {
+		e := NewStreamingEncoder(io.Discard, minEncoderBufSize)
+		e.SetSortKeys(true)
+		e.ObjStart()
+		e.Reset()
+		e.Null()
+		require.Equal(t, "null", e.String())
+	})
+}
+
+func BenchmarkEncoder_SetSortKeys(b *testing.B) {
+	e := GetEncoder()
+	e.SetSortKeys(true)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		e.Reset()
+		encodeUnsorted(e)
+	}
+}
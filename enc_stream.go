@@ -1,6 +1,9 @@
 package jx
 
-import "io"
+import (
+	"context"
+	"io"
+)
 
 const (
 	encoderBufSize    = 512
@@ -23,8 +26,51 @@ func NewStreamingEncoder(w io.Writer, bufSize int) *Encoder {
 	}
 }
 
+// stream returns stream state of underlying writer, which may be held while
+// writing sorted objects.
+func (e *Encoder) stream() *streamState {
+	if e.sortStream != nil {
+		return e.sortStream
+	}
+	return e.w.stream
+}
+
+// SetContext sets context of streaming mode. If context is done, next
+// flush fails with context error, which is returned by Err and Close.
+//
+// Does nothing if e is not in streaming mode. Context is kept on
+// ResetWriter.
+func (e *Encoder) SetContext(ctx context.Context) {
+	e.stream().setContext(ctx)
+}
+
+// SetFlushWatermark sets size of buffered data that triggers flush in
+// streaming mode. See Writer.SetFlushWatermark.
+func (e *Encoder) SetFlushWatermark(n int) {
+	e.stream().setWatermark(n)
+}
+
+// Err returns first misuse error recorded in checked mode or write error of
+// streaming mode, if any.
+//
+// Methods of Encoder return true on failure, use Err to get the cause.
+func (e *Encoder) Err() error {
+	if e.checkError != nil {
+		return e.checkError
+	}
+	return e.stream().err()
+}
+
 // Close flushes underlying buffer to writer in streaming mode.
 // Otherwise, it does nothing.
+//
+// In checked mode, also returns misuse error, if any.
 func (e *Encoder) Close() error {
-	return e.w.Close()
+	if err := e.w.Close(); err != nil {
+		return err
+	}
+	if e.checked {
+		return e.checkClose()
+	}
+	return nil
 }
@@ -1,6 +1,8 @@
 package jx
 
 import (
+	"bytes"
+	"context"
 	"io"
 	"strings"
 	"testing"
@@ -122,3 +124,81 @@ func TestStreamingEncoderBufferSize(t *testing.T) {
 	e = NewStreamingEncoder(io.Discard, minEncoderBufSize-1)
 	assert.Equal(t, minEncoderBufSize, cap(e.w.Buf))
 }
+
+func TestEncoder_Err(t *testing.T) {
+	errTest := errors.New("test")
+
+	ew := &errWriter{err: errTest}
+	e := NewStreamingEncoder(ew, minEncoderBufSize)
+	require.NoError(t, e.Err())
+	require.False(t, e.Str(strings.Repeat("a", minEncoderBufSize/2)))
+	require.True(t, e.Str(strings.Repeat("a", minEncoderBufSize)))
+	require.ErrorIs(t, e.Err(), errTest)
+	require.ErrorIs(t, e.w.Err(), errTest)
+
+	// Error is sticky.
+	require.True(t, e.Null())
+	require.ErrorIs(t, e.Close(), errTest)
+
+	e.ResetWriter(io.Discard)
+	require.NoError(t, e.Err())
+
+	var w Writer
+	require.NoError(t, w.Err())
+}
+
+func TestEncoder_SetContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var out bytes.Buffer
+	e := NewStreamingEncoder(&out, minEncoderBufSize)
+	e.SetContext(ctx)
+	e.ArrStart()
+	for i := 0; i < minEncoderBufSize; i++ {
+		require.False(t, e.Int(i))
+	}
+	written := out.Len()
+	require.NotZero(t, written)
+
+	cancel()
+	fail := false
+	for i := 0; i < minEncoderBufSize && !fail; i++ {
+		fail = e.Int(i)
+	}
+	require.True(t, fail)
+	require.ErrorIs(t, e.Err(), context.Canceled)
+	require.ErrorIs(t, e.Close(), context.Canceled)
+	require.Equal(t, written, out.Len())
+
+	// No-op if not streaming.
+	var w Writer
+	w.SetContext(ctx)
+	require.False(t, w.Null())
+}
+
+func TestEncoder_SetFlushWatermark(t *testing.T) {
+	var out bytes.Buffer
+	e := NewStreamingEncoder(&out, encoderBufSize)
+	e.SetFlushWatermark(8)
+	e.ArrStart()
+	e.Str("foo")
+	require.Zero(t, out.Len())
+	e.Str("bar")
+	require.Equal(t, `["foo","`, out.String())
+	require.Less(t, len(e.w.Buf), 8)
+	e.ArrEnd()
+	require.NoError(t, e.Close())
+	require.Equal(t, `["foo","bar"]`, out.String())
+
+	// Watermark is kept on ResetWriter.
+	out.Reset()
+	e.ResetWriter(&out)
+	e.Str("foobar")
+	require.Equal(t, `"foobar"`, out.String())
+
+	// No-op if not streaming.
+	var w Writer
+	w.SetFlushWatermark(1)
+	w.Null()
+	require.Equal(t, "null", w.String())
+}
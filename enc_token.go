@@ -0,0 +1,97 @@
+package jx
+
+import "math/big"
+
+// Token writes json token, like one returned by Decoder.Token.
+//
+// Commas and indentation are written automatically, as for other methods.
+// Relaxed mode keys and strings are written as json strings, relaxed mode
+// hex numbers are written as decimal ones.
+//
+// Returns true for invalid token and for NaN and Infinity, which can't be
+// represented in json.
+func (e *Encoder) Token(t Token) (fail bool) {
+	switch t.Kind {
+	case TokenObjStart:
+		return e.ObjStart()
+	case TokenObjEnd:
+		return e.ObjEnd()
+	case TokenArrStart:
+		return e.ArrStart()
+	case TokenArrEnd:
+		return e.ArrEnd()
+	case TokenKey:
+		// Same as FieldStart, but for raw key.
+		if e.checked && e.checkKey() {
+			return true
+		}
+		sep := len(e.w.Buf)
+		fail = e.separator()
+		e.addSortToken(sep, t.Raw)
+		fail = fail || e.tokenStr(t.Raw) || e.byte(':')
+		if e.indent > 0 {
+			fail = fail || e.byte(' ')
+		}
+		if len(e.first) > 0 {
+			e.first[e.current()] = true
+		}
+		return fail
+	case TokenStr:
+		return e.comma() || e.tokenStr(t.Raw)
+	case TokenNumber:
+		return e.tokenNum(t.Raw)
+	case TokenBool, TokenNull:
+		if len(t.Raw) == 0 {
+			return true
+		}
+		return e.Raw(t.Raw)
+	default:
+		return true
+	}
+}
+
+// tokenNum writes raw number token, converting relaxed mode hex numbers.
+func (e *Encoder) tokenNum(raw []byte) bool {
+	if len(raw) == 0 {
+		return true
+	}
+	neg, kind, digits := splitRelaxedNum(raw)
+	switch kind {
+	case relaxedNumJSON:
+		return e.Raw(raw)
+	case relaxedNumHex:
+		var v big.Int
+		if _, ok := v.SetString(string(digits), 16); !ok {
+			return true
+		}
+		if neg {
+			v.Neg(&v)
+		}
+		return e.Raw(v.Append(nil, 10))
+	default:
+		// NaN and Infinity.
+		return true
+	}
+}
+
+// tokenStr writes raw string or key token.
+func (e *Encoder) tokenStr(raw []byte) bool {
+	if len(raw) == 0 {
+		return true
+	}
+	switch raw[0] {
+	case '"':
+		return e.w.Raw(raw)
+	case '\'':
+		// Single-quoted string.
+		d := Decoder{buf: raw, tail: len(raw), relaxed: true}
+		v, err := d.str(value{})
+		if err != nil {
+			return true
+		}
+		return e.w.ByteStr(v.buf)
+	default:
+		// Identifier.
+		return e.w.ByteStr(raw)
+	}
+}
@@ -1,7 +1,10 @@
 package jx_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/go-faster/jx"
 )
@@ -62,6 +65,28 @@ func ExampleValid() {
 	// false
 }
 
+func ExampleParallelArr() {
+	d := jx.DecodeStr(`[{"name":"foo"},{"name":"bar"},{"name":"baz"}]`)
+
+	// Decode elements concurrently, results are in order of elements.
+	names, err := jx.ParallelArr(context.Background(), d, 2, func(ctx context.Context, d *jx.Decoder) (name string, err error) {
+		err = d.Obj(func(d *jx.Decoder, key string) error {
+			if key != "name" {
+				return d.Skip()
+			}
+			name, err = d.Str()
+			return err
+		})
+		return name, err
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(names)
+	// Output: [foo bar baz]
+}
+
 func ExampleDecoder_Capture() {
 	d := jx.DecodeStr(`["foo", "bar", "baz"]`)
 	var elems int
@@ -195,3 +220,67 @@ func ExampleEncoder_SetIdent() {
 	//   ]
 	// }
 }
+
+func ExampleAny() {
+	v, err := jx.DecodeStr(`{"name":"jx","tags":["json"]}`).Any()
+	if err != nil {
+		panic(err)
+	}
+	tags, _ := v.Get("tags")
+	tags.Append(jx.Any{Type: jx.AnyStr, Str: "fast"})
+	v.Set("tags", tags)
+	v.Set("stars", jx.Any{Type: jx.AnyNumber, Number: jx.Num("100")})
+
+	var e jx.Encoder
+	e.Any(v)
+	fmt.Println(e)
+	// Output:
+	// {"name":"jx","tags":["json","fast"],"stars":100}
+}
+
+func ExampleDecoder_Pointer() {
+	d := jx.DecodeStr(`{"spans":[{"name":"foo"},{"name":"bar"}]}`)
+	if err := d.Pointer("/spans/1/name"); err != nil {
+		panic(err)
+	}
+	name, err := d.Str()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(name)
+	// Output:
+	// bar
+}
+
+func ExampleLineReader() {
+	r := jx.NewLineReader(strings.NewReader(`{"level":"info","msg":"started"}
+{"level":"error","msg":
+{"level":"warn","msg":"slow"}
+`))
+	for r.Next() {
+		if err := r.Decode(func(d *jx.Decoder) error {
+			return d.Obj(func(d *jx.Decoder, key string) error {
+				if key != "msg" {
+					return d.Skip()
+				}
+				msg, err := d.Str()
+				if err != nil {
+					return err
+				}
+				fmt.Println(msg)
+				return nil
+			})
+		}); err != nil {
+			var lineErr *jx.LineError
+			if errors.As(err, &lineErr) {
+				fmt.Println("bad line", lineErr.Line)
+			}
+		}
+	}
+	if err := r.Err(); err != nil {
+		panic(err)
+	}
+	// Output: started
+	// bad line 2
+	// slow
+}
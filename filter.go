@@ -0,0 +1,305 @@
+package jx
+
+import (
+	"unicode/utf8"
+
+	"github.com/go-faster/errors"
+)
+
+// Filter rewrites json values, dropping, replacing or masking values at
+// configured paths and copying everything else as-is.
+//
+// Paths are JSON Pointers, like "/user/email", where reference token "*"
+// matches any single key or index and "**" matches zero or more of them, so
+// "/**/password" matches "password" key at any depth. Leading slash may be
+// omitted. If multiple paths match same value, first added is used.
+//
+// Filter is safe for concurrent use after configuration.
+type Filter struct {
+	rules []filterRule
+}
+
+type filterAction uint8
+
+const (
+	filterDrop filterAction = iota
+	filterReplace
+	filterMask
+)
+
+type filterRule struct {
+	tokens []string
+	index  []int // array index of token or -1
+	action filterAction
+	value  Raw
+}
+
+// filterState is position in rule tokens.
+type filterState struct {
+	rule int
+	pos  int
+}
+
+// Drop removes values matching pattern: members from objects and elements
+// from arrays. Top-level value is written as null.
+func (f *Filter) Drop(pattern string) error {
+	return f.add(pattern, filterDrop, nil)
+}
+
+// Replace writes value instead of values matching pattern.
+func (f *Filter) Replace(pattern string, value Raw) error {
+	if !Valid(value) {
+		return errors.Errorf("invalid value %q", value)
+	}
+	return f.add(pattern, filterReplace, append(Raw(nil), trimSpace(value)...))
+}
+
+// Mask replaces every character of strings matching pattern with "*",
+// preserving length. Values of other types are replaced with "***".
+func (f *Filter) Mask(pattern string) error {
+	return f.add(pattern, filterMask, nil)
+}
+
+func (f *Filter) add(pattern string, action filterAction, value Raw) error {
+	if pattern != "" && pattern[0] != '/' {
+		pattern = "/" + pattern
+	}
+	tokens, err := parsePointer(pattern)
+	if err != nil {
+		return errors.Wrap(err, "pattern")
+	}
+	index := make([]int, len(tokens))
+	for i, tok := range tokens {
+		index[i] = parsePointerIndex(tok)
+	}
+	f.rules = append(f.rules, filterRule{
+		tokens: tokens,
+		index:  index,
+		action: action,
+		value:  value,
+	})
+	return nil
+}
+
+// Apply reads next value from d and writes filtered value to w.
+func (f *Filter) Apply(d *Decoder, w *Writer) error {
+	p := filterer{f: f, w: w}
+	for i := range f.rules {
+		p.push(0, filterState{rule: i})
+	}
+	return d.decodeErr(p.value(d, 0, len(p.states)))
+}
+
+type filterer struct {
+	f *Filter
+	w *Writer
+	// states is stack of state sets, current set is states[lo:hi].
+	states []filterState
+	raw    rawReader
+}
+
+// fail returns write error.
+func (p *filterer) fail() error {
+	if err := p.w.Err(); err != nil {
+		return err
+	}
+	return errors.New("write failed")
+}
+
+// push adds state to set that starts at states[lo], following "**" that
+// matches nothing.
+func (p *filterer) push(lo int, s filterState) {
+	tokens := p.f.rules[s.rule].tokens
+	for {
+		for _, v := range p.states[lo:] {
+			if v == s {
+				return
+			}
+		}
+		p.states = append(p.states, s)
+		if s.pos >= len(tokens) || tokens[s.pos] != "**" {
+			return
+		}
+		s.pos++
+	}
+}
+
+// step pushes set of states after reference token, that is key or index,
+// if idx is not negative.
+func (p *filterer) step(lo, hi int, key []byte, idx int) {
+	next := len(p.states)
+	for i := lo; i < hi; i++ {
+		s := p.states[i]
+		r := &p.f.rules[s.rule]
+		if s.pos >= len(r.tokens) {
+			continue
+		}
+		switch tok := r.tokens[s.pos]; {
+		case tok == "**":
+			p.push(next, s)
+		case tok == "*",
+			idx < 0 && tok == string(key),
+			idx >= 0 && r.index[s.pos] == idx:
+			p.push(next, filterState{rule: s.rule, pos: s.pos + 1})
+		}
+	}
+}
+
+// match returns first rule that matches current value, or nil.
+func (p *filterer) match(lo, hi int) *filterRule {
+	found := -1
+	for i := lo; i < hi; i++ {
+		s := p.states[i]
+		if s.pos == len(p.f.rules[s.rule].tokens) && (found < 0 || s.rule < found) {
+			found = s.rule
+		}
+	}
+	if found < 0 {
+		return nil
+	}
+	return &p.f.rules[found]
+}
+
+// descend reports whether some rule may match value inside current one.
+func (p *filterer) descend(lo, hi int) bool {
+	for i := lo; i < hi; i++ {
+		s := p.states[i]
+		if s.pos < len(p.f.rules[s.rule].tokens) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply writes value according to rule.
+func (p *filterer) apply(d *Decoder, r *filterRule) error {
+	switch r.action {
+	case filterReplace:
+		if err := d.Skip(); err != nil {
+			return err
+		}
+		if p.w.Raw(r.value) {
+			return p.fail()
+		}
+	case filterMask:
+		if d.Next() != String {
+			if err := d.Skip(); err != nil {
+				return err
+			}
+			if p.w.RawStr(`"***"`) {
+				return p.fail()
+			}
+			return nil
+		}
+		s, err := d.StrBytes()
+		if err != nil {
+			return err
+		}
+		fail := p.w.byte('"')
+		for n := utf8.RuneCount(s); n > 0; n-- {
+			fail = fail || p.w.byte('*')
+		}
+		if fail || p.w.byte('"') {
+			return p.fail()
+		}
+	default:
+		if err := d.Skip(); err != nil {
+			return err
+		}
+		if p.w.Null() {
+			return p.fail()
+		}
+	}
+	return nil
+}
+
+// value filters value with current state set states[lo:hi].
+func (p *filterer) value(d *Decoder, lo, hi int) error {
+	if r := p.match(lo, hi); r != nil {
+		return p.apply(d, r)
+	}
+	switch tt := d.Next(); {
+	case tt == Object && p.descend(lo, hi):
+		return p.obj(d, lo, hi)
+	case tt == Array && p.descend(lo, hi):
+		return p.arr(d, lo, hi)
+	default:
+		raw, err := d.rawTo(&p.raw)
+		if err != nil {
+			return err
+		}
+		if p.w.Raw(trimSpace(raw)) {
+			return p.fail()
+		}
+		return nil
+	}
+}
+
+func (p *filterer) obj(d *Decoder, lo, hi int) error {
+	if p.w.ObjStart() {
+		return p.fail()
+	}
+	first := true
+	var rawKey []byte
+	if err := d.objBytes(func(d *Decoder, key []byte) error {
+		p.step(lo, hi, key, -1)
+		defer func() {
+			p.states = p.states[:hi]
+		}()
+		next := len(p.states)
+		if r := p.match(hi, next); r != nil && r.action == filterDrop {
+			return d.Skip()
+		}
+		if !first && p.w.Comma() {
+			return p.fail()
+		}
+		first = false
+		// Key is written as in input, unless it is relaxed mode key.
+		fail := false
+		if len(rawKey) > 0 && rawKey[0] == '"' {
+			fail = p.w.Raw(rawKey)
+		} else {
+			fail = p.w.ByteStr(key)
+		}
+		if fail || p.w.byte(':') {
+			return p.fail()
+		}
+		return p.value(d, hi, next)
+	}, &rawKey); err != nil {
+		return d.decodeErr(err)
+	}
+	if p.w.ObjEnd() {
+		return p.fail()
+	}
+	return nil
+}
+
+func (p *filterer) arr(d *Decoder, lo, hi int) error {
+	if p.w.ArrStart() {
+		return p.fail()
+	}
+	first := true
+	idx := 0
+	if err := d.Arr(func(d *Decoder) error {
+		p.step(lo, hi, nil, idx)
+		idx++
+		defer func() {
+			p.states = p.states[:hi]
+		}()
+		next := len(p.states)
+		if r := p.match(hi, next); r != nil && r.action == filterDrop {
+			return d.Skip()
+		}
+		if !first && p.w.Comma() {
+			return p.fail()
+		}
+		first = false
+		return p.value(d, hi, next)
+	}); err != nil {
+		return err
+	}
+	if p.w.ArrEnd() {
+		return p.fail()
+	}
+	return nil
+}
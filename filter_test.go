@@ -0,0 +1,163 @@
+package jx
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_Apply(t *testing.T) {
+	const input = `{
+		"user": {"name": "Alice", "email": "alice@example.com", "tags": [1, 2.0]},
+		"password": "secret",
+		"items": [{"id": 1, "password": "x"}, {"id": 2, "nested": {"password": null}}],
+		"token": "абв"
+	}`
+	for _, tt := range []struct {
+		Name   string
+		Setup  func(f *Filter) error
+		Input  string
+		Expect string
+	}{
+		{
+			Name:   "Empty",
+			Setup:  func(f *Filter) error { return nil },
+			Input:  ` {"a" : [1, 2.0]} `,
+			Expect: `{"a" : [1, 2.0]}`,
+		},
+		{
+			Name:   "Drop",
+			Setup:  func(f *Filter) error { return f.Drop("/user/email") },
+			Expect: `{"user":{"name":"Alice","tags":[1, 2.0]},"password":"secret","items":[{"id": 1, "password": "x"}, {"id": 2, "nested": {"password": null}}],"token":"абв"}`,
+		},
+		{
+			Name:   "DropAnyDepth",
+			Setup:  func(f *Filter) error { return f.Drop("**/password") },
+			Expect: `{"user":{"name":"Alice","email":"alice@example.com","tags":[1,2.0]},"items":[{"id":1},{"id":2,"nested":{}}],"token":"абв"}`,
+		},
+		{
+			Name: "Wildcard",
+			Setup: func(f *Filter) error {
+				return f.Replace("/items/*/id", Raw(` 0 `))
+			},
+			Expect: `{"user":{"name": "Alice", "email": "alice@example.com", "tags": [1, 2.0]},"password":"secret","items":[{"id":0,"password":"x"},{"id":0,"nested":{"password": null}}],"token":"абв"}`,
+		},
+		{
+			Name: "DropElem",
+			Setup: func(f *Filter) error {
+				return f.Drop("/items/0")
+			},
+			Expect: `{"user":{"name": "Alice", "email": "alice@example.com", "tags": [1, 2.0]},"password":"secret","items":[{"id": 2, "nested": {"password": null}}],"token":"абв"}`,
+		},
+		{
+			Name: "Mask",
+			Setup: func(f *Filter) error {
+				if err := f.Mask("/token"); err != nil {
+					return err
+				}
+				if err := f.Mask("/user/tags"); err != nil {
+					return err
+				}
+				return f.Mask("/**/password")
+			},
+			Expect: `{"user":{"name":"Alice","email":"alice@example.com","tags":"***"},"password":"******","items":[{"id":1,"password":"*"},{"id":2,"nested":{"password":"***"}}],"token":"***"}`,
+		},
+		{
+			Name: "FirstRuleWins",
+			Setup: func(f *Filter) error {
+				if err := f.Replace("/password", Raw(`"hidden"`)); err != nil {
+					return err
+				}
+				return f.Drop("**/password")
+			},
+			Input:  `{"password":"a","b":{"password":"c"}}`,
+			Expect: `{"password":"hidden","b":{}}`,
+		},
+		{
+			Name:   "Root",
+			Setup:  func(f *Filter) error { return f.Drop("") },
+			Input:  `{"a":1}`,
+			Expect: `null`,
+		},
+		{
+			Name:   "EscapedKey",
+			Setup:  func(f *Filter) error { return f.Drop("/a~1b") },
+			Input:  `{"a/b":1,"a":2}`,
+			Expect: `{"a":2}`,
+		},
+		{
+			Name:   "RawKeys",
+			Setup:  func(f *Filter) error { return f.Drop("/a~1b/x") },
+			Input:  `{"a\/b" : {"x":1,"\u0079":2}, "\"c\"":3}`,
+			Expect: `{"a\/b":{"\u0079":2},"\"c\"":3}`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.Name, func(t *testing.T) {
+			var f Filter
+			require.NoError(t, tt.Setup(&f))
+			in := tt.Input
+			if in == "" {
+				in = input
+			}
+			for _, d := range []*Decoder{
+				DecodeStr(in),
+				Decode(iotest.OneByteReader(strings.NewReader(in)), 1),
+			} {
+				var w Writer
+				require.NoError(t, f.Apply(d, &w))
+				require.Equal(t, tt.Expect, w.String())
+				require.True(t, Valid(w.Buf))
+			}
+		})
+	}
+}
+
+func TestFilter_Relaxed(t *testing.T) {
+	var f Filter
+	require.NoError(t, f.Drop("/b"))
+	d := DecodeStr(`{a: 1, 'b': 2, "c\/": 3}`)
+	d.SetRelaxed(true)
+	var w Writer
+	require.NoError(t, f.Apply(d, &w))
+	require.Equal(t, `{"a":1,"c\/":3}`, w.String())
+}
+
+func TestFilter_Error(t *testing.T) {
+	var f Filter
+	require.ErrorIs(t, f.Drop("/a~2"), ErrInvalidPointer)
+	require.Error(t, f.Replace("/a", Raw(`{`)))
+
+	require.NoError(t, f.Drop("/a/b"))
+	for _, input := range []string{
+		`{"a":{"b":}}`,
+		`{"a":{"c":[}}`,
+		`{"a":{"b":1`,
+		``,
+	} {
+		var w Writer
+		require.Error(t, f.Apply(DecodeStr(input), &w), input)
+	}
+}
+
+func BenchmarkFilter_Apply(b *testing.B) {
+	var f Filter
+	require.NoError(b, f.Drop("**/password"))
+	require.NoError(b, f.Mask("/person/name/fullName"))
+	data := benchData
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	var w Writer
+	d := DecodeBytes(data)
+	for i := 0; i < b.N; i++ {
+		w.Reset()
+		d.ResetBytes(data)
+		if err := f.Apply(d, &w); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
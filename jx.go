@@ -39,6 +39,10 @@ func GetDecoder() *Decoder {
 // PutDecoder puts *Decoder into pool.
 func PutDecoder(d *Decoder) {
 	d.Reset(nil)
+	d.SetLimits(Limits{})
+	d.SetUTF8Mode(UTF8Passthrough)
+	d.SetDisallowDuplicateKeys(false)
+	d.SetRelaxed(false)
 	decPool.Put(d)
 }
 
@@ -51,6 +55,9 @@ func GetEncoder() *Encoder {
 func PutEncoder(e *Encoder) {
 	e.Reset()
 	e.SetIdent(0)
+	e.SetSortKeys(false)
+	e.SetKeyLess(nil)
+	e.SetChecked(false)
 	encPool.Put(e)
 }
 
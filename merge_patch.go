@@ -0,0 +1,234 @@
+package jx
+
+import (
+	"github.com/go-faster/errors"
+)
+
+// MergePatch applies RFC 7396 merge patch to doc, writing result to e.
+//
+// Values of doc that are not changed by patch are written byte-for-byte,
+// new members are appended in order of patch.
+func MergePatch(e *Encoder, doc, patch Raw) error {
+	if err := mergePatch(e, doc, patch); err != nil {
+		return errors.Wrap(err, "merge patch")
+	}
+	return nil
+}
+
+// MergePatchDecoder reads doc and patch values and applies patch to doc,
+// like MergePatch.
+func MergePatchDecoder(e *Encoder, doc, patch *Decoder) error {
+	docRaw, err := doc.Raw()
+	if err != nil {
+		return errors.Wrap(err, "read doc")
+	}
+	patchRaw, err := patch.Raw()
+	if err != nil {
+		return errors.Wrap(err, "read patch")
+	}
+	return MergePatch(e, docRaw, patchRaw)
+}
+
+// mergeFail returns write error of e.
+func mergeFail(e *Encoder) error {
+	if err := e.Err(); err != nil {
+		return err
+	}
+	return errors.New("write failed")
+}
+
+func mergePatch(e *Encoder, doc, patch Raw) error {
+	switch patch.Type() {
+	case Object:
+	case Invalid:
+		return errors.Errorf("invalid patch %q", patch)
+	default:
+		if e.Raw(trimSpace(patch)) {
+			return mergeFail(e)
+		}
+		return nil
+	}
+	patchMembers, err := rawMembers(DecodeBytes(patch), nil)
+	if err != nil {
+		return errors.Wrap(err, "patch")
+	}
+	var docMembers []rawMember
+	if doc.Type() == Object {
+		if docMembers, err = rawMembers(DecodeBytes(doc), nil); err != nil {
+			return errors.Wrap(err, "doc")
+		}
+	}
+
+	patchIdx, docIdx := newMemberIndex(patchMembers), newMemberIndex(docMembers)
+
+	if e.ObjStart() {
+		return mergeFail(e)
+	}
+	for _, m := range docMembers {
+		i := patchIdx.find(m.Key)
+		if i < 0 {
+			if e.FieldStart(m.Key) || e.Raw(m.Value) {
+				return mergeFail(e)
+			}
+			continue
+		}
+		p := patchMembers[i].Value
+		if p.Type() == Null {
+			continue
+		}
+		if e.FieldStart(m.Key) {
+			return mergeFail(e)
+		}
+		if err := mergePatch(e, m.Value, p); err != nil {
+			return errors.Wrap(err, m.Key)
+		}
+	}
+	for i, p := range patchMembers {
+		if p.Value.Type() == Null ||
+			patchIdx.find(p.Key) != i ||
+			docIdx.find(p.Key) >= 0 {
+			continue
+		}
+		if e.FieldStart(p.Key) {
+			return mergeFail(e)
+		}
+		if err := mergePatch(e, nil, p.Value); err != nil {
+			return errors.Wrap(err, p.Key)
+		}
+	}
+	if e.ObjEnd() {
+		return mergeFail(e)
+	}
+	return nil
+}
+
+// CreateMergePatch writes RFC 7396 merge patch that transforms from to to.
+//
+// Numbers are compared by value, order of object members is ignored. Values
+// of to are written byte-for-byte. Returns error if to has null object member
+// that is not in from, because merge patch can't represent it.
+func CreateMergePatch(e *Encoder, from, to Raw) error {
+	if err := createMergePatch(e, from, to); err != nil {
+		return errors.Wrap(err, "create merge patch")
+	}
+	return nil
+}
+
+// CreateMergePatchDecoder reads from and to values and writes merge patch,
+// like CreateMergePatch.
+func CreateMergePatchDecoder(e *Encoder, from, to *Decoder) error {
+	fromRaw, err := from.Raw()
+	if err != nil {
+		return errors.Wrap(err, "read from")
+	}
+	toRaw, err := to.Raw()
+	if err != nil {
+		return errors.Wrap(err, "read to")
+	}
+	return CreateMergePatch(e, fromRaw, toRaw)
+}
+
+func createMergePatch(e *Encoder, from, to Raw) error {
+	if to.Type() == Invalid {
+		return errors.Errorf("invalid value %q", to)
+	}
+	if from.Type() != Object || to.Type() != Object {
+		if err := checkMergeNull(to); err != nil {
+			return err
+		}
+		if e.Raw(trimSpace(to)) {
+			return mergeFail(e)
+		}
+		return nil
+	}
+	fromMembers, err := rawMembers(DecodeBytes(from), nil)
+	if err != nil {
+		return errors.Wrap(err, "from")
+	}
+	toMembers, err := rawMembers(DecodeBytes(to), nil)
+	if err != nil {
+		return errors.Wrap(err, "to")
+	}
+
+	fromIdx, toIdx := newMemberIndex(fromMembers), newMemberIndex(toMembers)
+
+	if e.ObjStart() {
+		return mergeFail(e)
+	}
+	for i, m := range fromMembers {
+		if fromIdx.find(m.Key) != i || toIdx.find(m.Key) >= 0 {
+			continue
+		}
+		if e.FieldStart(m.Key) || e.Null() {
+			return mergeFail(e)
+		}
+	}
+	for i, m := range toMembers {
+		if toIdx.find(m.Key) != i {
+			continue
+		}
+		j := fromIdx.find(m.Key)
+		if j < 0 {
+			if m.Value.Type() == Null {
+				return errors.Errorf("%s: null member can't be added by merge patch", m.Key)
+			}
+		} else {
+			eq, err := rawEqual(fromMembers[j].Value, m.Value)
+			if err != nil {
+				return errors.Wrap(err, m.Key)
+			}
+			if eq {
+				continue
+			}
+			if m.Value.Type() == Null {
+				return errors.Errorf("%s: null member can't be set by merge patch", m.Key)
+			}
+		}
+		if e.FieldStart(m.Key) {
+			return mergeFail(e)
+		}
+		var from Raw
+		if j >= 0 {
+			from = fromMembers[j].Value
+		}
+		if err := createMergePatch(e, from, m.Value); err != nil {
+			return errors.Wrap(err, m.Key)
+		}
+	}
+	if e.ObjEnd() {
+		return mergeFail(e)
+	}
+	return nil
+}
+
+// checkMergeNull returns error if v is object with null member, that would
+// be removed by merge patch.
+func checkMergeNull(v Raw) error {
+	if v.Type() != Object {
+		return nil
+	}
+	return DecodeBytes(v).Obj(func(d *Decoder, key string) error {
+		v, err := d.Raw()
+		if err != nil {
+			return err
+		}
+		if v.Type() == Null {
+			return errors.Errorf("%s: null member can't be added by merge patch", key)
+		}
+		if err := checkMergeNull(v); err != nil {
+			return errors.Wrap(err, key)
+		}
+		return nil
+	})
+}
+
+// trimSpace removes leading and trailing whitespace of raw value.
+func trimSpace(v Raw) Raw {
+	for len(v) > 0 && spaceSet[v[0]] == 1 {
+		v = v[1:]
+	}
+	for len(v) > 0 && spaceSet[v[len(v)-1]] == 1 {
+		v = v[:len(v)-1]
+	}
+	return v
+}
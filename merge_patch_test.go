@@ -0,0 +1,153 @@
+package jx
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatch(t *testing.T) {
+	for _, tt := range []struct {
+		Doc, Patch, Result string
+	}{
+		// RFC 7396, Appendix A.
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+
+		// Untouched values are written as is.
+		{`{"a": [1, 2.0,  {"x" : 1e0}], "b": 1}`, `{"b":2}`, `{"a":[1, 2.0,  {"x" : 1e0}],"b":2}`},
+		{` {"a":1} `, ` [ 1 ] `, `[ 1 ]`},
+		// Last of duplicate members is used.
+		{`{}`, `{"a":1,"a":2}`, `{"a":2}`},
+	} {
+		var e Encoder
+		require.NoError(t, MergePatch(&e, Raw(tt.Doc), Raw(tt.Patch)))
+		require.Equal(t, tt.Result, e.String(), "%s + %s", tt.Doc, tt.Patch)
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		for _, tt := range []struct {
+			Doc, Patch string
+		}{
+			{`{}`, ``},
+			{`{}`, `{"a":}`},
+			{`{"a":{"b":}}`, `{"a":{"c":1}}`},
+		} {
+			var e Encoder
+			require.Error(t, MergePatch(&e, Raw(tt.Doc), Raw(tt.Patch)), tt.Patch)
+		}
+	})
+	t.Run("WriteErr", func(t *testing.T) {
+		doc := Raw(`{"a":"` + strings.Repeat("a", minEncoderBufSize) + `"}`)
+		e := NewStreamingEncoder(&errWriter{err: io.ErrClosedPipe}, minEncoderBufSize)
+		require.ErrorIs(t, MergePatch(e, doc, Raw(`{"b":1}`)), io.ErrClosedPipe)
+
+		e = NewStreamingEncoder(&errWriter{err: io.ErrClosedPipe}, minEncoderBufSize)
+		require.ErrorIs(t, CreateMergePatch(e, Raw(`{}`), doc), io.ErrClosedPipe)
+	})
+	t.Run("ManyKeys", func(t *testing.T) {
+		// Keys are found by index, not by scan of object.
+		const n = 20_000
+		var doc, patch, result Encoder
+		doc.Obj(func(doc *Encoder) {
+			patch.Obj(func(patch *Encoder) {
+				result.Obj(func(result *Encoder) {
+					for i := 0; i < n; i++ {
+						key := strconv.Itoa(i)
+						doc.Field(key, func(e *Encoder) { e.Int(i) })
+						switch {
+						case i%3 == 0:
+							patch.Field(key, func(e *Encoder) { e.Null() })
+						case i%3 == 1:
+							patch.Field(key, func(e *Encoder) { e.Int(-i) })
+							result.Field(key, func(e *Encoder) { e.Int(-i) })
+						default:
+							result.Field(key, func(e *Encoder) { e.Int(i) })
+						}
+					}
+				})
+			})
+		})
+		var e Encoder
+		require.NoError(t, MergePatch(&e, doc.Bytes(), patch.Bytes()))
+		require.Equal(t, result.String(), e.String())
+
+		e.Reset()
+		require.NoError(t, CreateMergePatch(&e, doc.Bytes(), result.Bytes()))
+		eq, err := rawEqual(patch.Bytes(), e.Bytes())
+		require.NoError(t, err)
+		require.True(t, eq)
+	})
+	t.Run("Decoder", func(t *testing.T) {
+		var e Encoder
+		doc := Decode(strings.NewReader(`{"a":1,"b":[1]} {"a":2}`), 4)
+		patch := DecodeStr(`{"a":null,"c":true}`)
+		require.NoError(t, MergePatchDecoder(&e, doc, patch))
+		require.Equal(t, `{"b":[1],"c":true}`, e.String())
+
+		e.Reset()
+		require.NoError(t, CreateMergePatchDecoder(&e, doc, DecodeStr(`{"a":3}`)))
+		require.Equal(t, `{"a":3}`, e.String())
+
+		require.Error(t, MergePatchDecoder(&e, doc, patch))
+	})
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	for _, tt := range []struct {
+		From, To, Patch string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"a":"b","b":"c"}`, `{"b":"c"}`},
+		{`{"a":"b","b":"c"}`, `{"b":"c"}`, `{"a":null}`},
+		{`{"a":{"b":"c","d":1}}`, `{"a":{"b":"d","d":1.0}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[1,2]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"a":1,"b":2}`, `{"b":2,"a":1}`, `{}`},
+		{`{"a":null}`, `{"a":null}`, `{}`},
+		{`[1]`, `{"a":{"b":[null]}}`, `{"a":{"b":[null]}}`},
+		{`{"a":1}`, `[1, 2]`, `[1, 2]`},
+		{`1`, `1`, `1`},
+	} {
+		var e Encoder
+		require.NoError(t, CreateMergePatch(&e, Raw(tt.From), Raw(tt.To)))
+		require.Equal(t, tt.Patch, e.String(), "%s -> %s", tt.From, tt.To)
+
+		// Patch must transform from to to.
+		var result Encoder
+		require.NoError(t, MergePatch(&result, Raw(tt.From), e.Bytes()))
+		eq, err := rawEqual(result.Bytes(), Raw(tt.To))
+		require.NoError(t, err)
+		require.True(t, eq, "%s != %s", result, tt.To)
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		for _, tt := range []struct {
+			From, To string
+		}{
+			{`{}`, ``},
+			{`{}`, `{"a":null}`},
+			{`{"a":1}`, `{"a":null}`},
+			{`{"a":1}`, `{"a":{"b":{"c":null}}}`},
+			{`1`, `{"a":null}`},
+		} {
+			var e Encoder
+			require.Error(t, CreateMergePatch(&e, Raw(tt.From), Raw(tt.To)), tt.To)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+//go:build linux
+
+package jx
+
+import (
+	"io"
+	"os"
+	"syscall"
+
+	"github.com/go-faster/errors"
+)
+
+// MappedFile is read-only memory-mapped file.
+//
+// Decoder of mapped file does not copy input, so values returned by Raw
+// and StrBytes reference mapped memory and are valid until Close.
+type MappedFile struct {
+	data []byte
+}
+
+// OpenMapped maps file into memory.
+func OpenMapped(name string) (*MappedFile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := stat.Size()
+	if size == 0 {
+		return &MappedFile{}, nil
+	}
+	if int64(int(size)) != size {
+		return nil, errors.Errorf("file is too large: %d bytes", size)
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, errors.Wrap(err, "mmap")
+	}
+	return &MappedFile{data: data}, nil
+}
+
+// Bytes returns mapped memory.
+func (m *MappedFile) Bytes() []byte {
+	return m.data
+}
+
+// Len returns size of file.
+func (m *MappedFile) Len() int {
+	return len(m.data)
+}
+
+// ReadAt implements io.ReaderAt.
+func (m *MappedFile) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if off >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Decoder returns new Decoder of mapped file.
+func (m *MappedFile) Decoder() *Decoder {
+	return DecodeBytes(m.data)
+}
+
+// Close unmaps file. Decoders and values referencing mapped memory must not
+// be used after Close.
+func (m *MappedFile) Close() error {
+	if m.data == nil {
+		return nil
+	}
+	data := m.data
+	m.data = nil
+	return syscall.Munmap(data)
+}
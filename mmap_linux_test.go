@@ -0,0 +1,56 @@
+//go:build linux
+
+package jx
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenMapped(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "data.json")
+	require.NoError(t, os.WriteFile(name, benchData, 0o600))
+
+	m, err := OpenMapped(name)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, m.Close())
+	}()
+	require.Equal(t, len(benchData), m.Len())
+	require.Equal(t, benchData, m.Bytes())
+
+	raw, err := m.Decoder().Raw()
+	require.NoError(t, err)
+	require.Equal(t, bytes.TrimSpace(benchData), []byte(raw))
+
+	d := DecodeReaderAt(m, int64(m.Len()), 64)
+	raw, err = d.Raw()
+	require.NoError(t, err)
+	require.Equal(t, bytes.TrimSpace(benchData), []byte(raw))
+
+	buf := make([]byte, 10)
+	n, err := m.ReadAt(buf, int64(m.Len()-5))
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, 5, n)
+	_, err = m.ReadAt(buf, -1)
+	require.Error(t, err)
+
+	t.Run("Empty", func(t *testing.T) {
+		name := filepath.Join(dir, "empty.json")
+		require.NoError(t, os.WriteFile(name, nil, 0o600))
+		m, err := OpenMapped(name)
+		require.NoError(t, err)
+		require.ErrorIs(t, m.Decoder().Skip(), io.EOF)
+		require.NoError(t, m.Close())
+	})
+	t.Run("NotExist", func(t *testing.T) {
+		_, err := OpenMapped(filepath.Join(dir, "missing.json"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
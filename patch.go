@@ -0,0 +1,361 @@
+package jx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+// ErrPatchTestFailed means that value of "test" operation of JSON Patch is
+// not equal to value in document.
+var ErrPatchTestFailed = errors.New("patch: test failed")
+
+// PatchOp is single operation of RFC 6902 JSON Patch.
+type PatchOp struct {
+	// Op is one of "add", "remove", "replace", "move", "copy" and "test".
+	Op string
+	// Path is JSON Pointer to target location.
+	Path string
+	// From is JSON Pointer to source location of "move" and "copy".
+	From string
+	// Value of "add", "replace" and "test".
+	Value Raw
+}
+
+// PatchError is error of JSON Patch operation.
+type PatchError struct {
+	// Index of operation in patch.
+	Index int
+	Op    PatchOp
+	Err   error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("patch op %d (%s %q): %s", e.Index, e.Op.Op, e.Op.Path, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// Patch is RFC 6902 JSON Patch document.
+type Patch []PatchOp
+
+// Decode decodes JSON Patch document, validating operations.
+//
+// Returns *PatchError if operation is invalid.
+func (p *Patch) Decode(d *Decoder) error {
+	ops := (*p)[:0]
+	if err := d.Arr(func(d *Decoder) error {
+		var op PatchOp
+		hasPath, hasFrom := false, false
+		if err := d.Obj(func(d *Decoder, key string) (err error) {
+			switch key {
+			case "op":
+				op.Op, err = d.Str()
+			case "path":
+				hasPath = true
+				op.Path, err = d.Str()
+			case "from":
+				hasFrom = true
+				op.From, err = d.Str()
+			case "value":
+				v, err := d.Raw()
+				if err != nil {
+					return err
+				}
+				op.Value = append(Raw(nil), v...)
+			default:
+				// Members of other names are ignored.
+				return d.Skip()
+			}
+			return err
+		}); err != nil {
+			return err
+		}
+
+		var err error
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				err = errors.New(`missing "value"`)
+			}
+		case "move", "copy":
+			if !hasFrom {
+				err = errors.New(`missing "from"`)
+			}
+		case "remove":
+		default:
+			err = errors.Errorf("unknown op %q", op.Op)
+		}
+		if !hasPath {
+			err = errors.New(`missing "path"`)
+		}
+		if err != nil {
+			return &PatchError{Index: len(ops), Op: op, Err: err}
+		}
+		ops = append(ops, op)
+		return nil
+	}); err != nil {
+		return err
+	}
+	*p = ops
+	return nil
+}
+
+// Apply applies patch to doc, writing result to w.
+//
+// Values of doc that are not changed by patch are written byte-for-byte.
+// Returns *PatchError that wraps ErrPatchTestFailed if "test" operation
+// fails, ErrPointerNotFound if location does not exist and ErrInvalidPointer
+// if pointer is malformed. On error, w may contain partial result.
+//
+// Each operation writes whole document again, result of all but last
+// operation is written to one of two intermediate buffers, so cost is
+// proportional to count of operations times document size. Objects and
+// arrays on path of operation are rewritten, other values are copied.
+func (p Patch) Apply(w *Writer, doc Raw) error {
+	var (
+		// Buffers for intermediate results.
+		buf  [2]Writer
+		move Writer
+	)
+	doc = trimSpace(doc)
+	for i, op := range p {
+		out := w
+		if i < len(p)-1 {
+			out = &buf[i%2]
+			out.Reset()
+		}
+		if err := applyPatchOp(out, &move, doc, op); err != nil {
+			return &PatchError{Index: i, Op: op, Err: err}
+		}
+		doc = out.Buf
+	}
+	if len(p) == 0 && w.Raw(doc) {
+		return patchFail(w)
+	}
+	return nil
+}
+
+// patchFail returns write error of w.
+func patchFail(w *Writer) error {
+	if err := w.Err(); err != nil {
+		return err
+	}
+	return errors.New("write failed")
+}
+
+func applyPatchOp(w, move *Writer, doc Raw, op PatchOp) error {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+	value := trimSpace(op.Value)
+	switch op.Op {
+	case "add":
+		return patchEdit(w, doc, path, patchAdd, value, nil)
+	case "remove":
+		return patchEdit(w, doc, path, patchRemove, nil, nil)
+	case "replace":
+		return patchEdit(w, doc, path, patchReplace, value, nil)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return errors.Wrap(err, "from")
+		}
+		if op.Op == "copy" || op.From == op.Path {
+			v, err := patchGet(doc, op.From)
+			if err != nil {
+				return errors.Wrap(err, "from")
+			}
+			if op.Op == "copy" {
+				return patchEdit(w, doc, path, patchAdd, v, nil)
+			}
+			if w.Raw(doc) {
+				return patchFail(w)
+			}
+			return nil
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return errors.Errorf("can't move %q to its child", op.From)
+		}
+		// Removed value references doc, that is not overwritten.
+		var v Raw
+		move.Reset()
+		if err := patchEdit(move, doc, from, patchRemove, nil, &v); err != nil {
+			return errors.Wrap(err, "from")
+		}
+		return patchEdit(w, move.Buf, path, patchAdd, v, nil)
+	case "test":
+		v, err := patchGet(doc, op.Path)
+		if err != nil {
+			return err
+		}
+		eq, err := rawEqual(v, value)
+		if err != nil {
+			return err
+		}
+		if !eq {
+			return errors.Wrapf(ErrPatchTestFailed, "got %s", v)
+		}
+		if w.Raw(doc) {
+			return patchFail(w)
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown op %q", op.Op)
+	}
+}
+
+// patchGet returns value at location ptr.
+func patchGet(doc Raw, ptr string) (Raw, error) {
+	d := DecodeBytes(doc)
+	if err := d.Pointer(ptr); err != nil {
+		return nil, err
+	}
+	return d.Raw()
+}
+
+type patchEditKind uint8
+
+const (
+	patchAdd patchEditKind = iota
+	patchRemove
+	patchReplace
+)
+
+// patchEdit writes doc with value at location path added, removed or
+// replaced to w. Removed or replaced value is set to old, if it is not nil.
+func patchEdit(w *Writer, doc Raw, path []string, kind patchEditKind, value Raw, old *Raw) error {
+	if len(path) == 0 {
+		if kind == patchRemove {
+			return errors.New("can't remove whole document")
+		}
+		if w.Raw(value) {
+			return patchFail(w)
+		}
+		return nil
+	}
+	tok, last := path[0], len(path) == 1
+	switch tt := doc.Type(); tt {
+	case Object:
+		members, err := rawMembers(DecodeBytes(doc), nil)
+		if err != nil {
+			return err
+		}
+		found := newMemberIndex(members).find(tok)
+		if found < 0 && (!last || kind != patchAdd) {
+			return errors.Wrapf(ErrPointerNotFound, "key %q", tok)
+		}
+		if w.ObjStart() {
+			return patchFail(w)
+		}
+		first := true
+		field := func(key string) bool {
+			if !first && w.Comma() {
+				return true
+			}
+			first = false
+			return w.FieldStart(key)
+		}
+		for i, m := range members {
+			switch {
+			case i != found:
+				if field(m.Key) || w.Raw(m.Value) {
+					return patchFail(w)
+				}
+			case !last:
+				if field(m.Key) {
+					return patchFail(w)
+				}
+				if err := patchEdit(w, m.Value, path[1:], kind, value, old); err != nil {
+					return err
+				}
+			case kind != patchRemove:
+				if field(m.Key) || w.Raw(value) {
+					return patchFail(w)
+				}
+				fallthrough
+			default:
+				if old != nil {
+					*old = m.Value
+				}
+			}
+		}
+		if found < 0 && (field(tok) || w.Raw(value)) {
+			return patchFail(w)
+		}
+		if w.ObjEnd() {
+			return patchFail(w)
+		}
+	case Array:
+		elems, err := rawElems(DecodeBytes(doc), nil)
+		if err != nil {
+			return err
+		}
+		insert := last && kind == patchAdd
+		idx, limit := parsePointerIndex(tok), len(elems)
+		if insert {
+			if tok == "-" {
+				idx = len(elems)
+			}
+			limit++
+		}
+		if idx < 0 || idx >= limit {
+			return errors.Wrapf(ErrPointerNotFound, "index %q", tok)
+		}
+		if w.ArrStart() {
+			return patchFail(w)
+		}
+		first := true
+		comma := func() bool {
+			if first {
+				first = false
+				return false
+			}
+			return w.Comma()
+		}
+		elem := func(v Raw) bool {
+			return comma() || w.Raw(v)
+		}
+		for i, v := range elems {
+			switch {
+			case i != idx:
+				if elem(v) {
+					return patchFail(w)
+				}
+			case !last:
+				if comma() {
+					return patchFail(w)
+				}
+				if err := patchEdit(w, v, path[1:], kind, value, old); err != nil {
+					return err
+				}
+			case kind == patchAdd:
+				if elem(value) || elem(v) {
+					return patchFail(w)
+				}
+			default:
+				if kind == patchReplace && elem(value) {
+					return patchFail(w)
+				}
+				if old != nil {
+					*old = v
+				}
+			}
+		}
+		if idx == len(elems) && elem(value) {
+			return patchFail(w)
+		}
+		if w.ArrEnd() {
+			return patchFail(w)
+		}
+	case Invalid:
+		return errors.Errorf("invalid document %q", doc)
+	default:
+		return errors.Wrapf(ErrPointerNotFound, "token %q: %s has no children", tok, tt)
+	}
+	return nil
+}
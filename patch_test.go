@@ -0,0 +1,163 @@
+package jx
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-faster/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func applyPatch(t testing.TB, doc, patch string) (string, error) {
+	t.Helper()
+	var p Patch
+	require.NoError(t, p.Decode(DecodeStr(patch)))
+	var w Writer
+	if err := p.Apply(&w, Raw(doc)); err != nil {
+		return "", err
+	}
+	return w.String(), nil
+}
+
+func TestPatch_Apply(t *testing.T) {
+	for _, tt := range []struct {
+		Name   string
+		Doc    string
+		Patch  string
+		Result string
+	}{
+		// RFC 6902, Appendix A.
+		{"AddMember", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`},
+		{"AddElem", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"RemoveMember", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"RemoveElem", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"Replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{
+			"Move",
+			`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{"MoveElem", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{
+			"Test",
+			`{"baz":"qux","foo":["a",2,"c"]}`,
+			`[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{"AddNested", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{"IgnoreUnknown", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, `{"foo":"bar","baz":"qux"}`},
+		{"AddArray", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{"TestEscaped", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{"TestNumber", `{"a":1}`, `[{"op":"test","path":"/a","value":1.0}]`, `{"a":1}`},
+
+		// Other cases.
+		{"Empty", ` {"a" : 1} `, `[]`, `{"a" : 1}`},
+		{"Root", `{"a":1}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+		{"Copy", `{"a":{"b":[1, 2]}}`, `[{"op":"copy","from":"/a/b","path":"/c"}]`, `{"a":{"b":[1, 2]},"c":[1, 2]}`},
+		{"MoveSelf", `{"a":1}`, `[{"op":"move","from":"/a","path":"/a"}]`, `{"a":1}`},
+		{"AddExisting", `{"a":1,"b":2}`, `[{"op":"add","path":"/a","value":3}]`, `{"a":3,"b":2}`},
+		{
+			"Sequence",
+			`{"a": {"x" : [1,  2]}, "b": "keep  me"}`,
+			`[
+				{"op":"add","path":"/c","value":1},
+				{"op":"remove","path":"/a/x/0"},
+				{"op":"replace","path":"/c","value":2},
+				{"op":"copy","from":"/c","path":"/a/y"}
+			]`,
+			`{"a":{"x":[2],"y":2},"b":"keep  me","c":2}`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := applyPatch(t, tt.Doc, tt.Patch)
+			require.NoError(t, err)
+			require.Equal(t, tt.Result, got)
+		})
+	}
+}
+
+func TestPatch_ApplyError(t *testing.T) {
+	for _, tt := range []struct {
+		Name  string
+		Doc   string
+		Patch string
+		Index int
+		Err   error
+	}{
+		{"TestFailed", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, 0, ErrPatchTestFailed},
+		{"AddNoParent", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, 0, ErrPointerNotFound},
+		{"RemoveMissing", `{"a":1}`, `[{"op":"test","path":"/a","value":1},{"op":"remove","path":"/b"}]`, 1, ErrPointerNotFound},
+		{"IndexOutOfRange", `[1,2]`, `[{"op":"add","path":"/3","value":1}]`, 0, ErrPointerNotFound},
+		{"ReplaceDash", `[1,2]`, `[{"op":"replace","path":"/-","value":1}]`, 0, ErrPointerNotFound},
+		{"LeadingZero", `[1,2]`, `[{"op":"remove","path":"/01"}]`, 0, ErrPointerNotFound},
+		{"Scalar", `{"a":1}`, `[{"op":"add","path":"/a/b","value":1}]`, 0, ErrPointerNotFound},
+		{"InvalidPointer", `{"a":1}`, `[{"op":"remove","path":"a"}]`, 0, ErrInvalidPointer},
+		{"InvalidFrom", `{"a":1}`, `[{"op":"copy","from":"/a~2","path":"/b"}]`, 0, ErrInvalidPointer},
+		{"MissingFrom", `{"a":1}`, `[{"op":"move","from":"/b","path":"/c"}]`, 0, ErrPointerNotFound},
+	} {
+		tt := tt
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := applyPatch(t, tt.Doc, tt.Patch)
+			require.ErrorIs(t, err, tt.Err)
+			var pe *PatchError
+			require.ErrorAs(t, err, &pe)
+			require.Equal(t, tt.Index, pe.Index)
+		})
+	}
+	t.Run("MoveToChild", func(t *testing.T) {
+		_, err := applyPatch(t, `{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/c"}]`)
+		require.Error(t, err)
+	})
+	t.Run("RemoveRoot", func(t *testing.T) {
+		_, err := applyPatch(t, `{}`, `[{"op":"remove","path":""}]`)
+		require.Error(t, err)
+	})
+	t.Run("WriteErr", func(t *testing.T) {
+		doc := Raw(`{"a":"` + strings.Repeat("a", 64) + `","b":[1,2,3]}`)
+		for _, patch := range []string{
+			`[]`,
+			`[{"op":"add","path":"/b/1","value":0}]`,
+			`[{"op":"remove","path":"/b/0"},{"op":"replace","path":"/a","value":1}]`,
+			`[{"op":"test","path":"/b/0","value":1}]`,
+		} {
+			var p Patch
+			require.NoError(t, p.Decode(DecodeStr(patch)))
+			w := Writer{Buf: make([]byte, 0, 16)}
+			w.ResetWriter(&errWriter{err: io.ErrClosedPipe})
+			require.ErrorIs(t, p.Apply(&w, doc), io.ErrClosedPipe, patch)
+		}
+	})
+}
+
+func TestPatch_Decode(t *testing.T) {
+	var p Patch
+	require.NoError(t, p.Decode(DecodeStr(`[
+		{"op":"add","path":"/a","value":null},
+		{"path":"/b","op":"move","from":"/a"}
+	]`)))
+	require.Equal(t, Patch{
+		{Op: "add", Path: "/a", Value: Raw("null")},
+		{Op: "move", Path: "/b", From: "/a"},
+	}, p)
+
+	for _, input := range []string{
+		`{}`,
+		`[{"op":"add","path":"/a"}]`,
+		`[{"op":"move","path":"/a"}]`,
+		`[{"op":"remove"}]`,
+		`[{"op":"foo","path":"/a"}]`,
+		`[{"path":"/a"}]`,
+		`[{"op":"add","path":"/a","value":}]`,
+	} {
+		require.Error(t, p.Decode(DecodeStr(input)), input)
+	}
+
+	var pe *PatchError
+	err := p.Decode(DecodeStr(`[{"op":"remove","path":"/a"},{"op":"test","path":"/a"}]`))
+	require.True(t, errors.As(err, &pe))
+	require.Equal(t, 1, pe.Index)
+	require.Equal(t, `patch op 1 (test "/a"): missing "value"`, pe.Error())
+}
@@ -0,0 +1,315 @@
+package jx
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/go-faster/errors"
+)
+
+// rawEqual reports whether json values a and b are semantically equal:
+// numbers are compared by value, objects regardless of order of keys.
+//
+// Unlike Num.Equal, that also compares format, numbers 1.0 and 1 are equal.
+func rawEqual(a, b []byte) (bool, error) {
+	da, db := DecodeBytes(a), DecodeBytes(b)
+	ta, tb := da.Next(), db.Next()
+	if ta != tb {
+		return false, nil
+	}
+	switch ta {
+	case String:
+		sa, err := da.StrBytes()
+		if err != nil {
+			return false, err
+		}
+		sb, err := db.StrBytes()
+		if err != nil {
+			return false, err
+		}
+		return bytes.Equal(sa, sb), nil
+	case Number:
+		na, err := da.Num()
+		if err != nil {
+			return false, err
+		}
+		nb, err := db.Num()
+		if err != nil {
+			return false, err
+		}
+		c, err := numCmp(na, nb)
+		return c == 0, err
+	case Bool:
+		va, err := da.Bool()
+		if err != nil {
+			return false, err
+		}
+		vb, err := db.Bool()
+		if err != nil {
+			return false, err
+		}
+		return va == vb, nil
+	case Null:
+		if err := da.Null(); err != nil {
+			return false, err
+		}
+		return true, db.Null()
+	case Array:
+		ea, err := rawElems(da, nil)
+		if err != nil {
+			return false, err
+		}
+		eb, err := rawElems(db, nil)
+		if err != nil {
+			return false, err
+		}
+		if len(ea) != len(eb) {
+			return false, nil
+		}
+		for i := range ea {
+			if ok, err := rawEqual(ea[i], eb[i]); err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case Object:
+		ma, err := rawMembers(da, nil)
+		if err != nil {
+			return false, err
+		}
+		mb, err := rawMembers(db, nil)
+		if err != nil {
+			return false, err
+		}
+		// Members with duplicate keys are overridden by last one.
+		la, lb := newMemberIndex(ma).last(), newMemberIndex(mb).last()
+		if len(la) != len(lb) {
+			return false, nil
+		}
+		for i := range la {
+			m, n := ma[la[i]], mb[lb[i]]
+			if m.Key != n.Key {
+				return false, nil
+			}
+			if ok, err := rawEqual(m.Value, n.Value); err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	default:
+		return false, da.Skip()
+	}
+}
+
+// rawHash returns hash of json value, that is equal for values equal by
+// rawEqual. Numbers are hashed by float64 value.
+func rawHash(raw []byte) (uint64, error) {
+	return rawHashDec(DecodeBytes(raw))
+}
+
+// FNV-1a parameters.
+const (
+	hashOffset = 14695981039346656037
+	hashPrime  = 1099511628211
+)
+
+func hashBytes(h uint64, b []byte) uint64 {
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= hashPrime
+	}
+	return h
+}
+
+func hashUint(h, v uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		h ^= v & 0xff
+		h *= hashPrime
+		v >>= 8
+	}
+	return h
+}
+
+func rawHashDec(d *Decoder) (uint64, error) {
+	tt := d.Next()
+	h := hashUint(hashOffset, uint64(tt))
+	switch tt {
+	case String:
+		s, err := d.StrBytes()
+		if err != nil {
+			return 0, err
+		}
+		return hashBytes(h, s), nil
+	case Number:
+		n, err := d.Num()
+		if err != nil {
+			return 0, err
+		}
+		f, err := strconv.ParseFloat(string(n), 64)
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return 0, errors.Wrap(err, "parse")
+		}
+		if f == 0 {
+			f = 0 // -0
+		}
+		return hashUint(h, math.Float64bits(f)), nil
+	case Bool:
+		v, err := d.Bool()
+		if err != nil {
+			return 0, err
+		}
+		if v {
+			h = hashUint(h, 1)
+		}
+		return h, nil
+	case Null:
+		return h, d.Null()
+	case Array:
+		err := d.Arr(func(d *Decoder) error {
+			e, err := rawHashDec(d)
+			h = hashUint(h, e)
+			return err
+		})
+		return h, err
+	case Object:
+		members, err := rawMembers(d, nil)
+		if err != nil {
+			return 0, err
+		}
+		for _, i := range newMemberIndex(members).last() {
+			m := members[i]
+			v, err := rawHash(m.Value)
+			if err != nil {
+				return 0, err
+			}
+			h = hashUint(hashBytes(h, []byte(m.Key)), v)
+		}
+		return h, nil
+	default:
+		return 0, d.Skip()
+	}
+}
+
+// rawMember is object member with raw value.
+type rawMember struct {
+	Key   string
+	Value Raw
+}
+
+// rawElems appends raw elements of array to buf.
+func rawElems(d *Decoder, buf []Raw) ([]Raw, error) {
+	err := d.Arr(func(d *Decoder) error {
+		v, err := d.Raw()
+		if err != nil {
+			return err
+		}
+		buf = append(buf, v)
+		return nil
+	})
+	return buf, err
+}
+
+// rawMembers appends members of object with raw values to buf.
+func rawMembers(d *Decoder, buf []rawMember) ([]rawMember, error) {
+	err := d.Obj(func(d *Decoder, key string) error {
+		v, err := d.Raw()
+		if err != nil {
+			return err
+		}
+		buf = append(buf, rawMember{Key: key, Value: v})
+		return nil
+	})
+	return buf, err
+}
+
+// memberIndex is index of object members sorted by key, to find members
+// without building map.
+type memberIndex struct {
+	members []rawMember
+	sorted  []int // indexes of members, stable sorted by key
+}
+
+func newMemberIndex(members []rawMember) memberIndex {
+	sorted := make([]int, len(members))
+	for i := range sorted {
+		sorted[i] = i
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return members[sorted[i]].Key < members[sorted[j]].Key
+	})
+	return memberIndex{members: members, sorted: sorted}
+}
+
+// find returns index of last member with key, or -1.
+func (x memberIndex) find(key string) int {
+	i := sort.Search(len(x.sorted), func(i int) bool {
+		return x.members[x.sorted[i]].Key > key
+	})
+	if i == 0 || x.members[x.sorted[i-1]].Key != key {
+		return -1
+	}
+	return x.sorted[i-1]
+}
+
+// last returns indexes of last member with each key, sorted by key.
+func (x memberIndex) last() []int {
+	var r []int
+	for i, idx := range x.sorted {
+		if i+1 < len(x.sorted) && x.members[x.sorted[i+1]].Key == x.members[idx].Key {
+			continue
+		}
+		r = append(r, idx)
+	}
+	return r
+}
+
+// maxRatExp is maximum exponent of number that is compared exactly.
+const maxRatExp = 10000
+
+// numCmp compares values of numbers a and b, returning -1, 0 or 1.
+//
+// Num.Equal compares numbers as written, so value is compared by float64
+// and, if floats are equal, by exact rational value.
+func numCmp(a, b Num) (int, error) {
+	if bytes.Equal(a, b) {
+		return 0, nil
+	}
+	fa, err := strconv.ParseFloat(string(a), 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, errors.Wrap(err, "parse")
+	}
+	fb, err := strconv.ParseFloat(string(b), 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, errors.Wrap(err, "parse")
+	}
+	switch {
+	case fa < fb:
+		return -1, nil
+	case fa > fb:
+		return 1, nil
+	}
+	// Floats are equal, but numbers may differ in precision.
+	ra, ok := numRat(a)
+	if !ok {
+		return 0, nil
+	}
+	rb, ok := numRat(b)
+	if !ok {
+		return 0, nil
+	}
+	return ra.Cmp(rb), nil
+}
+
+// numRat returns exact value of number, if its exponent is not too big.
+func numRat(n Num) (*big.Rat, bool) {
+	if i := bytes.IndexAny(n, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(string(n[i+1:]))
+		if err != nil || exp > maxRatExp || exp < -maxRatExp {
+			return nil, false
+		}
+	}
+	return new(big.Rat).SetString(string(n))
+}
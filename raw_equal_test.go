@@ -0,0 +1,41 @@
+package jx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawEqual(t *testing.T) {
+	for _, tt := range []struct {
+		A, B  string
+		Equal bool
+	}{
+		{`1`, `1.0`, true},
+		{`1e2`, `100`, true},
+		{`0.1`, `0.10000000000000001`, false},
+		{`"a"`, `"a"`, true},
+		{`[1, {"a": null}]`, `[1,{"a":null}]`, true},
+		{`[1, 2]`, `[2, 1]`, false},
+		{`{"a": 1, "b": [2]}`, `{"b": [2.0], "a": 1}`, true},
+		{`{"a": 1, "b": 2}`, `{"b": 2.0, "a": 3, "a": 1}`, true},
+		{`{"a": 1, "a": 1}`, `{"a": 1, "b": 1}`, false},
+		{`{"a": 1}`, `{"b": 1}`, false},
+		{`{}`, `[]`, false},
+		{`-0`, `0.0`, true},
+	} {
+		eq, err := rawEqual(Raw(tt.A), Raw(tt.B))
+		require.NoError(t, err)
+		require.Equal(t, tt.Equal, eq, "%s %s", tt.A, tt.B)
+		if tt.Equal {
+			ha, err := rawHash(Raw(tt.A))
+			require.NoError(t, err)
+			hb, err := rawHash(Raw(tt.B))
+			require.NoError(t, err)
+			require.Equal(t, ha, hb, "hash of %s %s", tt.A, tt.B)
+		}
+		eq, err = rawEqual(Raw(tt.B), Raw(tt.A))
+		require.NoError(t, err)
+		require.Equal(t, tt.Equal, eq, "%s %s", tt.B, tt.A)
+	}
+}
@@ -0,0 +1,235 @@
+package jx
+
+import (
+	"io"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+// Schema is compiled JSON Schema, draft 2020-12.
+//
+// Supported are core vocabulary, including $ref and $dynamicRef to local and
+// embedded resources, applicator, unevaluated and validation vocabularies.
+// Format is annotation only and remote references are not supported.
+//
+// Schema is immutable and safe for concurrent use.
+type Schema struct {
+	root *schema
+}
+
+// SchemaError is single schema violation.
+type SchemaError struct {
+	// Pointer is RFC 6901 JSON Pointer to invalid value in instance.
+	Pointer string
+	// Keyword is path of failed keyword in schema, like "/properties/a/type".
+	Keyword string
+	// Message describes violation.
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	return "at " + strconv.Quote(e.Pointer) + " (keyword " + strconv.Quote(e.Keyword) + "): " + e.Message
+}
+
+// SchemaErrors is list of schema violations, returned by Schema.Validate.
+type SchemaErrors []SchemaError
+
+func (e SchemaErrors) Error() string {
+	var b strings.Builder
+	for i, err := range e {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// schemaType is set of json types.
+type schemaType uint8
+
+const (
+	schemaNull schemaType = 1 << iota
+	schemaBool
+	schemaObject
+	schemaArray
+	schemaNumber
+	schemaString
+	schemaInteger
+)
+
+var schemaTypes = map[string]schemaType{
+	"null":    schemaNull,
+	"boolean": schemaBool,
+	"object":  schemaObject,
+	"array":   schemaArray,
+	"number":  schemaNumber,
+	"string":  schemaString,
+	"integer": schemaInteger,
+}
+
+func (t schemaType) String() string {
+	var names []string
+	for _, name := range []string{"null", "boolean", "object", "array", "number", "string", "integer"} {
+		if t&schemaTypes[name] != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// schemaNum is number from schema.
+type schemaNum struct {
+	raw Num
+	set bool
+}
+
+// schemaProp is subschema for property.
+type schemaProp struct {
+	name   string
+	schema *schema
+}
+
+// schemaPattern is subschema for property name pattern.
+type schemaPattern struct {
+	re     *regexp.Regexp
+	schema *schema
+}
+
+// schemaDep is dependency of property.
+type schemaDep struct {
+	name     string
+	required []string
+	schema   *schema
+}
+
+// schema is compiled schema object.
+type schema struct {
+	// Boolean schema, if isBool.
+	isBool    bool
+	boolValue bool
+
+	id            string
+	anchor        string
+	dynamicAnchor string
+	ref           string
+	refKw         string // "$ref" or "$dynamicRef"
+	refTo         *schema
+	// dynamicRef is name of dynamic anchor, if $dynamicRef initially
+	// resolves to schema with such $dynamicAnchor.
+	dynamicRef string
+	// res is root of schema resource and dynamic maps names of dynamic
+	// anchors of resource to schemas, if schema is root of resource.
+	res     *schema
+	dynamic map[string]*schema
+
+	types    schemaType
+	enum     []Raw
+	constVal Raw
+	hasConst bool
+
+	multipleOf       *big.Rat
+	maximum          schemaNum
+	exclusiveMaximum schemaNum
+	minimum          schemaNum
+	exclusiveMinimum schemaNum
+
+	maxLength int
+	minLength int
+	pattern   *regexp.Regexp
+
+	prefixItems      []*schema
+	items            *schema
+	contains         *schema
+	maxContains      int
+	minContains      int
+	maxItems         int
+	minItems         int
+	uniqueItems      bool
+	unevaluatedItems *schema
+
+	properties            []schemaProp
+	patternProperties     []schemaPattern
+	additionalProperties  *schema
+	propertyNames         *schema
+	maxProperties         int
+	minProperties         int
+	required              []string
+	dependentRequired     []schemaDep
+	dependentSchemas      []schemaDep
+	unevaluatedProperties *schema
+
+	allOf []*schema
+	anyOf []*schema
+	oneOf []*schema
+	not   *schema
+	if_   *schema
+	then  *schema
+	else_ *schema
+}
+
+func newSchema() *schema {
+	return &schema{
+		maxLength:     -1,
+		minLength:     -1,
+		maxContains:   -1,
+		minContains:   -1,
+		maxItems:      -1,
+		minItems:      -1,
+		maxProperties: -1,
+		minProperties: -1,
+	}
+}
+
+// CompileSchema compiles JSON Schema from json.
+func CompileSchema(data []byte) (*Schema, error) {
+	c := schemaCompiler{
+		locations: map[string]*schema{},
+	}
+	root, err := c.compile(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "compile")
+	}
+	return &Schema{root: root}, nil
+}
+
+// ValidateBuffered reads next value from d and validates it against schema.
+//
+// Value is read by Decoder.Raw, so whole value is buffered: applicators
+// like anyOf and unevaluatedProperties may check it multiple times. Each
+// object and array is decoded once and shared by all keywords.
+//
+// Returns SchemaErrors if value is invalid.
+func (s *Schema) ValidateBuffered(d *Decoder) error {
+	raw, err := d.Raw()
+	if err != nil {
+		return err
+	}
+	v := schemaValidator{}
+	ok := v.validate(s.root, newSchemaValue(raw), nil)
+	if v.err != nil {
+		return v.err
+	}
+	if !ok {
+		return v.errs
+	}
+	return nil
+}
+
+// ValidateBytes validates single json value against schema.
+//
+// Returns SchemaErrors if value is invalid.
+func (s *Schema) ValidateBytes(data []byte) error {
+	d := DecodeBytes(data)
+	if err := s.ValidateBuffered(d); err != nil {
+		return err
+	}
+	if err := d.Skip(); err != io.EOF {
+		return d.decodeErr(errors.Wrap(err, "unexpected trialing data"))
+	}
+	return nil
+}
@@ -0,0 +1,393 @@
+package jx
+
+import (
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-faster/errors"
+)
+
+// schemaNode is location of compiled schema in document.
+type schemaNode struct {
+	schema *schema
+	parent int    // index of parent node or -1
+	rel    string // JSON Pointer relative to parent
+	res    int    // index of resource root node
+
+	base   string // base URI, without fragment
+	resPtr string // JSON Pointer relative to resource with base URI
+	docPtr string // JSON Pointer relative to document
+}
+
+type schemaCompiler struct {
+	nodes []schemaNode
+	// locations maps absolute URI with fragment to schema.
+	locations map[string]*schema
+}
+
+func (c *schemaCompiler) compile(data []byte) (*schema, error) {
+	d := DecodeBytes(data)
+	root, err := c.node(d, -1, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Skip(); err != io.EOF {
+		return nil, d.decodeErr(errors.Wrap(err, "unexpected trialing data"))
+	}
+
+	// Nodes are in pre-order, so parent is always resolved before child.
+	for i := range c.nodes {
+		n := &c.nodes[i]
+		n.res = i
+		if n.parent >= 0 {
+			p := c.nodes[n.parent]
+			n.base, n.resPtr, n.docPtr = p.base, p.resPtr+n.rel, p.docPtr+n.rel
+			n.res = p.res
+		}
+		if id := n.schema.id; id != "" {
+			u, err := resolveSchemaURI(n.base, id)
+			if err != nil {
+				return nil, errors.Wrapf(err, "$id at %q", n.docPtr)
+			}
+			n.base, _ = splitSchemaURI(u)
+			n.resPtr = ""
+			n.res = i
+		}
+		s := n.schema
+		s.res = c.nodes[n.res].schema
+		c.register(n.base+"#"+n.resPtr, s)
+		c.register(c.nodes[0].base+"#"+n.docPtr, s)
+		if a := s.anchor; a != "" {
+			c.register(n.base+"#"+a, s)
+		}
+		if a := s.dynamicAnchor; a != "" {
+			c.register(n.base+"#"+a, s)
+			if s.res.dynamic == nil {
+				s.res.dynamic = map[string]*schema{}
+			}
+			s.res.dynamic[a] = s
+		}
+	}
+	for _, n := range c.nodes {
+		s := n.schema
+		if s.refKw == "" {
+			continue
+		}
+		u, err := resolveSchemaURI(n.base, s.ref)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s at %q", s.refKw, n.docPtr)
+		}
+		to, ok := c.locations[u]
+		if !ok {
+			return nil, errors.Errorf("%s at %q: unresolved reference %q", s.refKw, n.docPtr, s.ref)
+		}
+		s.refTo = to
+		if _, name := splitSchemaURI(u); s.refKw == "$dynamicRef" && name != "" && to.dynamicAnchor == name {
+			s.dynamicRef = name
+		}
+	}
+	return root, nil
+}
+
+func (c *schemaCompiler) register(uri string, s *schema) {
+	if _, ok := c.locations[uri]; !ok {
+		c.locations[uri] = s
+	}
+}
+
+// resolveSchemaURI resolves reference against base, returning absolute URI
+// with fragment, like "http://example.com/schema#/$defs/a".
+func resolveSchemaURI(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	u := b.ResolveReference(r)
+	fragment := u.Fragment
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String() + "#" + fragment, nil
+}
+
+// splitSchemaURI splits URI to base and fragment.
+func splitSchemaURI(uri string) (base, fragment string) {
+	if i := strings.IndexByte(uri, '#'); i >= 0 {
+		return uri[:i], uri[i+1:]
+	}
+	return uri, ""
+}
+
+// node compiles schema, adding it to nodes.
+func (c *schemaCompiler) node(d *Decoder, parent int, rel string) (*schema, error) {
+	switch tt := d.Next(); tt {
+	case Bool:
+		v, err := d.Bool()
+		if err != nil {
+			return nil, err
+		}
+		s := &schema{isBool: true, boolValue: v}
+		c.nodes = append(c.nodes, schemaNode{schema: s, parent: parent, rel: rel})
+		return s, nil
+	case Object:
+		s := newSchema()
+		idx := len(c.nodes)
+		c.nodes = append(c.nodes, schemaNode{schema: s, parent: parent, rel: rel})
+		if err := d.Obj(func(d *Decoder, key string) error {
+			if err := c.keyword(d, s, idx, key); err != nil {
+				return errors.Wrap(err, key)
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, errors.Errorf("schema must be object or boolean, got %s", tt)
+	}
+}
+
+// subschema compiles schema of keyword.
+func (c *schemaCompiler) subschema(d *Decoder, parent int, path ...string) (*schema, error) {
+	var rel []byte
+	for _, tok := range path {
+		rel = appendPointerToken(rel, tok)
+	}
+	return c.node(d, parent, string(rel))
+}
+
+func (c *schemaCompiler) keyword(d *Decoder, s *schema, idx int, key string) (err error) {
+	switch key {
+	case "$id":
+		s.id, err = d.Str()
+	case "$anchor":
+		s.anchor, err = d.Str()
+	case "$dynamicAnchor":
+		s.dynamicAnchor, err = d.Str()
+	case "$ref", "$dynamicRef":
+		s.refKw = key
+		s.ref, err = d.Str()
+	case "$defs", "definitions", "properties", "patternProperties", "dependentSchemas":
+		return d.Obj(func(d *Decoder, name string) error {
+			sub, err := c.subschema(d, idx, key, name)
+			if err != nil {
+				return err
+			}
+			switch key {
+			case "properties":
+				s.properties = append(s.properties, schemaProp{name: name, schema: sub})
+			case "patternProperties":
+				re, err := regexp.Compile(name)
+				if err != nil {
+					return err
+				}
+				s.patternProperties = append(s.patternProperties, schemaPattern{re: re, schema: sub})
+			case "dependentSchemas":
+				s.dependentSchemas = append(s.dependentSchemas, schemaDep{name: name, schema: sub})
+			}
+			return nil
+		})
+	case "prefixItems", "allOf", "anyOf", "oneOf":
+		var subs []*schema
+		if err := d.Arr(func(d *Decoder) error {
+			sub, err := c.subschema(d, idx, key, strconv.Itoa(len(subs)))
+			if err != nil {
+				return err
+			}
+			subs = append(subs, sub)
+			return nil
+		}); err != nil {
+			return err
+		}
+		switch key {
+		case "prefixItems":
+			s.prefixItems = subs
+		case "allOf":
+			s.allOf = subs
+		case "anyOf":
+			s.anyOf = subs
+		case "oneOf":
+			s.oneOf = subs
+		}
+	case "items", "contains", "additionalProperties", "propertyNames", "not",
+		"if", "then", "else", "unevaluatedItems", "unevaluatedProperties":
+		sub, err := c.subschema(d, idx, key)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "items":
+			s.items = sub
+		case "contains":
+			s.contains = sub
+		case "additionalProperties":
+			s.additionalProperties = sub
+		case "propertyNames":
+			s.propertyNames = sub
+		case "not":
+			s.not = sub
+		case "if":
+			s.if_ = sub
+		case "then":
+			s.then = sub
+		case "else":
+			s.else_ = sub
+		case "unevaluatedItems":
+			s.unevaluatedItems = sub
+		case "unevaluatedProperties":
+			s.unevaluatedProperties = sub
+		}
+	case "type":
+		return c.types(d, s)
+	case "enum":
+		return d.Arr(func(d *Decoder) error {
+			v, err := d.Raw()
+			if err != nil {
+				return err
+			}
+			s.enum = append(s.enum, append(Raw(nil), v...))
+			return nil
+		})
+	case "const":
+		v, err := d.Raw()
+		if err != nil {
+			return err
+		}
+		s.constVal, s.hasConst = append(Raw(nil), v...), true
+	case "multipleOf":
+		n, err := c.num(d)
+		if err != nil {
+			return err
+		}
+		r, ok := numRat(n.raw)
+		if !ok || r.Sign() <= 0 {
+			return errors.Errorf("invalid value %s", n.raw)
+		}
+		s.multipleOf = r
+	case "maximum", "exclusiveMaximum", "minimum", "exclusiveMinimum":
+		n, err := c.num(d)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "maximum":
+			s.maximum = n
+		case "exclusiveMaximum":
+			s.exclusiveMaximum = n
+		case "minimum":
+			s.minimum = n
+		case "exclusiveMinimum":
+			s.exclusiveMinimum = n
+		}
+	case "maxLength", "minLength", "maxItems", "minItems", "maxContains", "minContains",
+		"maxProperties", "minProperties":
+		n, err := c.count(d)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "maxLength":
+			s.maxLength = n
+		case "minLength":
+			s.minLength = n
+		case "maxItems":
+			s.maxItems = n
+		case "minItems":
+			s.minItems = n
+		case "maxContains":
+			s.maxContains = n
+		case "minContains":
+			s.minContains = n
+		case "maxProperties":
+			s.maxProperties = n
+		case "minProperties":
+			s.minProperties = n
+		}
+	case "pattern":
+		p, err := d.Str()
+		if err != nil {
+			return err
+		}
+		if s.pattern, err = regexp.Compile(p); err != nil {
+			return err
+		}
+	case "uniqueItems":
+		s.uniqueItems, err = d.Bool()
+	case "required":
+		s.required, err = c.strings(d)
+	case "dependentRequired":
+		return d.Obj(func(d *Decoder, name string) error {
+			required, err := c.strings(d)
+			if err != nil {
+				return err
+			}
+			s.dependentRequired = append(s.dependentRequired, schemaDep{name: name, required: required})
+			return nil
+		})
+	default:
+		// Unknown keyword or annotation.
+		return d.Skip()
+	}
+	return err
+}
+
+func (c *schemaCompiler) types(d *Decoder, s *schema) error {
+	add := func(d *Decoder) error {
+		name, err := d.Str()
+		if err != nil {
+			return err
+		}
+		t, ok := schemaTypes[name]
+		if !ok {
+			return errors.Errorf("unknown type %q", name)
+		}
+		s.types |= t
+		return nil
+	}
+	if d.Next() == Array {
+		return d.Arr(add)
+	}
+	return add(d)
+}
+
+func (c *schemaCompiler) num(d *Decoder) (schemaNum, error) {
+	n, err := d.Num()
+	if err != nil {
+		return schemaNum{}, err
+	}
+	if n.Str() {
+		return schemaNum{}, errors.New("number expected")
+	}
+	return schemaNum{raw: append(Num(nil), n...), set: true}, nil
+}
+
+// count reads non-negative integer, like 10 or 10.0.
+func (c *schemaCompiler) count(d *Decoder) (int, error) {
+	n, err := d.Num()
+	if err != nil {
+		return 0, err
+	}
+	v, err := n.Int64()
+	if err != nil || v < 0 || n.Str() {
+		return 0, errors.Errorf("non-negative integer expected, got %s", n)
+	}
+	return int(v), nil
+}
+
+func (c *schemaCompiler) strings(d *Decoder) (r []string, _ error) {
+	err := d.Arr(func(d *Decoder) error {
+		v, err := d.Str()
+		if err != nil {
+			return err
+		}
+		r = append(r, v)
+		return nil
+	})
+	return r, err
+}
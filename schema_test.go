@@ -0,0 +1,379 @@
+package jx
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/go-faster/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	for _, tt := range []struct {
+		Name    string
+		Schema  string
+		Valid   []string
+		Invalid []string
+	}{
+		{
+			Name:    "Bool",
+			Schema:  `false`,
+			Invalid: []string{`1`, `{}`},
+		},
+		{
+			Name:    "Type",
+			Schema:  `{"type":["string","null"]}`,
+			Valid:   []string{`"a"`, `null`},
+			Invalid: []string{`1`, `true`, `[]`, `{}`},
+		},
+		{
+			Name:    "Integer",
+			Schema:  `{"type":"integer"}`,
+			Valid:   []string{`1`, `-10`, `1.0`, `1e3`, `1e100000`},
+			Invalid: []string{`1.5`, `1e-3`, `"1"`},
+		},
+		{
+			Name:    "Enum",
+			Schema:  `{"enum":[1,"a",{"b":[1,2]}]}`,
+			Valid:   []string{`1.0`, `10e-1`, `"a"`, `{"b":[1,2.00]}`},
+			Invalid: []string{`2`, `"b"`, `{"b":[2,1]}`, `{"b":[1,2],"c":1}`},
+		},
+		{
+			Name:    "Const",
+			Schema:  `{"const":{"a":1,"b":null}}`,
+			Valid:   []string{`{"b":null,"a":1}`, `{"a":1.0,"b":null}`},
+			Invalid: []string{`{"a":1}`, `{"a":1,"b":false}`},
+		},
+		{
+			Name:    "Range",
+			Schema:  `{"minimum":1,"exclusiveMaximum":10}`,
+			Valid:   []string{`1`, `9.999999999999999999999`, `"not a number"`},
+			Invalid: []string{`0.99999999999999999999`, `10`, `1e1`},
+		},
+		{
+			Name:    "MultipleOf",
+			Schema:  `{"multipleOf":0.0001}`,
+			Valid:   []string{`0.0075`, `19.99`, `0`},
+			Invalid: []string{`0.00751`},
+		},
+		{
+			Name:    "String",
+			Schema:  `{"minLength":2,"maxLength":3,"pattern":"^[a-zа-я]+$"}`,
+			Valid:   []string{`"ab"`, `"абв"`, `"аб"`},
+			Invalid: []string{`"a"`, `"abcd"`, `"AB"`},
+		},
+		{
+			Name:    "Items",
+			Schema:  `{"prefixItems":[{"type":"string"}],"items":{"type":"number"},"minItems":1,"maxItems":3}`,
+			Valid:   []string{`["a"]`, `["a",1,2]`},
+			Invalid: []string{`[]`, `[1]`, `["a","b"]`, `["a",1,2,3]`},
+		},
+		{
+			Name:    "Contains",
+			Schema:  `{"contains":{"const":1},"maxContains":2}`,
+			Valid:   []string{`[1]`, `[0,1,1.0]`},
+			Invalid: []string{`[]`, `[0]`, `[1,1,1]`},
+		},
+		{
+			Name:   "MinContainsZero",
+			Schema: `{"contains":{"const":1},"minContains":0}`,
+			Valid:  []string{`[]`, `[0]`},
+		},
+		{
+			Name:    "UniqueItems",
+			Schema:  `{"uniqueItems":true}`,
+			Valid:   []string{`[1,2,"1"]`, `[{"a":1},{"a":2}]`},
+			Invalid: []string{`[1,1.0]`, `[{"a":1,"b":2},{"b":2,"a":1}]`},
+		},
+		{
+			Name: "Object",
+			Schema: `{
+				"properties":{"a":{"type":"integer"}},
+				"patternProperties":{"^x-":{"type":"string"}},
+				"additionalProperties":false,
+				"required":["a"],
+				"maxProperties":2
+			}`,
+			Valid:   []string{`{"a":1}`, `{"a":1,"x-b":"c"}`},
+			Invalid: []string{`{}`, `{"a":"1"}`, `{"a":1,"b":2}`, `{"a":1,"x-b":1}`, `{"a":1,"x-b":"","x-c":""}`},
+		},
+		{
+			Name:    "PropertyNames",
+			Schema:  `{"propertyNames":{"maxLength":2}}`,
+			Valid:   []string{`{}`, `{"ab":1}`},
+			Invalid: []string{`{"abc":1}`},
+		},
+		{
+			Name:    "Dependent",
+			Schema:  `{"dependentRequired":{"a":["b"]},"dependentSchemas":{"c":{"required":["d"]}}}`,
+			Valid:   []string{`{}`, `{"a":1,"b":1}`, `{"c":1,"d":1}`},
+			Invalid: []string{`{"a":1}`, `{"c":1}`},
+		},
+		{
+			Name:    "Combinators",
+			Schema:  `{"allOf":[{"type":"number"}],"anyOf":[{"minimum":10},{"maximum":0}],"not":{"const":100}}`,
+			Valid:   []string{`10`, `-1`},
+			Invalid: []string{`5`, `100`, `"a"`},
+		},
+		{
+			Name:    "OneOf",
+			Schema:  `{"oneOf":[{"multipleOf":2},{"multipleOf":3}]}`,
+			Valid:   []string{`2`, `3`},
+			Invalid: []string{`6`, `5`},
+		},
+		{
+			Name:    "IfThenElse",
+			Schema:  `{"if":{"type":"string"},"then":{"minLength":1},"else":{"type":"null"}}`,
+			Valid:   []string{`"a"`, `null`},
+			Invalid: []string{`""`, `1`},
+		},
+		{
+			Name: "Ref",
+			Schema: `{
+				"$defs":{
+					"node":{"type":"object","properties":{"next":{"$ref":"#/$defs/node"}}},
+					"a~b":{"$anchor":"ab","type":"string"}
+				},
+				"properties":{"list":{"$ref":"#/$defs/node"},"s":{"$ref":"#ab"},"t":{"$ref":"#/$defs/a~0b"}}
+			}`,
+			Valid:   []string{`{"list":{"next":{"next":{}}},"s":"","t":""}`},
+			Invalid: []string{`{"list":{"next":{"next":1}}}`, `{"s":1}`, `{"t":1}`},
+		},
+		{
+			Name: "RefID",
+			Schema: `{
+				"$id":"https://example.com/root.json",
+				"$defs":{
+					"a":{"$id":"a.json","$defs":{"b":{"type":"integer"}}}
+				},
+				"properties":{
+					"a":{"$ref":"a.json#/$defs/b"},
+					"b":{"$ref":"https://example.com/a.json#/$defs/b"}
+				}
+			}`,
+			Valid:   []string{`{"a":1,"b":2}`},
+			Invalid: []string{`{"a":"1"}`, `{"b":"2"}`},
+		},
+		{
+			Name: "DynamicRef",
+			Schema: `{
+				"$id":"https://example.com/strings",
+				"$ref":"list",
+				"$defs":{
+					"items":{"$dynamicAnchor":"items","type":"string"},
+					"list":{
+						"$id":"list",
+						"type":"array",
+						"items":{"$dynamicRef":"#items"},
+						"$defs":{"items":{"$dynamicAnchor":"items"}}
+					}
+				}
+			}`,
+			Valid:   []string{`[]`, `["a","b"]`},
+			Invalid: []string{`[1]`, `["a",null]`},
+		},
+		{
+			Name: "DynamicRefDefault",
+			Schema: `{
+				"$id":"https://example.com/any",
+				"$ref":"list",
+				"$defs":{
+					"list":{
+						"$id":"list",
+						"type":"array",
+						"items":{"$dynamicRef":"#items"},
+						"$defs":{"items":{"$dynamicAnchor":"items","type":"number"}}
+					}
+				}
+			}`,
+			Valid:   []string{`[1,2]`},
+			Invalid: []string{`["a"]`},
+		},
+		{
+			Name: "DynamicRefAnchor",
+			// Dynamic reference to $anchor is resolved like $ref.
+			Schema: `{
+				"$id":"https://example.com/root",
+				"$ref":"list",
+				"$defs":{
+					"items":{"$dynamicAnchor":"items","type":"string"},
+					"list":{
+						"$id":"list",
+						"items":{"$dynamicRef":"#items"},
+						"$defs":{"items":{"$anchor":"items","type":"number"}}
+					}
+				}
+			}`,
+			Valid:   []string{`[1]`},
+			Invalid: []string{`["a"]`},
+		},
+		{
+			Name: "UnevaluatedProperties",
+			Schema: `{
+				"properties":{"a":true},
+				"allOf":[{"properties":{"b":true}}],
+				"anyOf":[{"properties":{"c":true},"required":["c"]},true],
+				"unevaluatedProperties":false
+			}`,
+			Valid:   []string{`{"a":1,"b":2}`, `{"a":1,"c":2}`},
+			Invalid: []string{`{"a":1,"d":2}`},
+		},
+		{
+			Name:    "UnevaluatedItems",
+			Schema:  `{"prefixItems":[true],"contains":{"const":"x"},"unevaluatedItems":{"type":"number"}}`,
+			Valid:   []string{`[null,"x",1]`},
+			Invalid: []string{`[null,"x","y"]`},
+		},
+	} {
+		tt := tt
+		t.Run(tt.Name, func(t *testing.T) {
+			s, err := CompileSchema([]byte(tt.Schema))
+			require.NoError(t, err)
+			for _, input := range tt.Valid {
+				require.NoError(t, s.ValidateBytes([]byte(input)), input)
+			}
+			for _, input := range tt.Invalid {
+				err := s.ValidateBytes([]byte(input))
+				var errs SchemaErrors
+				require.ErrorAs(t, err, &errs, input)
+				require.NotEmpty(t, errs)
+			}
+		})
+	}
+}
+
+func TestSchema_Errors(t *testing.T) {
+	s, err := CompileSchema([]byte(`{
+		"$defs":{"positive":{"exclusiveMinimum":0}},
+		"properties":{
+			"items":{"items":{"$ref":"#/$defs/positive"}},
+			"a/b":{"type":"string"}
+		},
+		"required":["id"]
+	}`))
+	require.NoError(t, err)
+
+	err = s.ValidateBytes([]byte(`{"items":[1,-1,0],"a/b":1}`))
+	var errs SchemaErrors
+	require.ErrorAs(t, err, &errs)
+	var got []SchemaError
+	for _, e := range errs {
+		e.Message = ""
+		got = append(got, e)
+	}
+	require.Equal(t, []SchemaError{
+		{Pointer: "", Keyword: "/required"},
+		{Pointer: "/items/1", Keyword: "/properties/items/items/$ref/exclusiveMinimum"},
+		{Pointer: "/items/2", Keyword: "/properties/items/items/$ref/exclusiveMinimum"},
+		{Pointer: "/a~1b", Keyword: "/properties/a~1b/type"},
+	}, got)
+	require.Equal(t, `property "id" is required`, errs[0].Message)
+	require.Equal(t, `at "/a~1b" (keyword "/properties/a~1b/type"): expected string, got number`, errs[3].Error())
+}
+
+func TestSchema_ValidateReader(t *testing.T) {
+	s, err := CompileSchema([]byte(`{"type":"array","items":{"type":"integer"}}`))
+	require.NoError(t, err)
+
+	d := Decode(iotest.OneByteReader(strings.NewReader(`[1,2,3] [1,"2"] [`)), 2)
+	require.NoError(t, s.ValidateBuffered(d))
+	var errs SchemaErrors
+	require.ErrorAs(t, s.ValidateBuffered(d), &errs)
+	require.Equal(t, "/1", errs[0].Pointer)
+	require.Error(t, s.ValidateBuffered(d))
+	require.False(t, errors.As(s.ValidateBuffered(d), &errs))
+}
+
+func TestCompileSchema_Error(t *testing.T) {
+	for _, input := range []string{
+		``,
+		`1`,
+		`{"type":"foo"}`,
+		`{"type":1}`,
+		`{"pattern":"("}`,
+		`{"minLength":-1}`,
+		`{"multipleOf":0}`,
+		`{"properties":{"a":1}}`,
+		`{"$ref":"#/$defs/missing"}`,
+		`{"$ref":"https://example.com/remote.json"}`,
+		`{} {}`,
+	} {
+		_, err := CompileSchema([]byte(input))
+		require.Error(t, err, input)
+	}
+}
+
+func TestSchema_Large(t *testing.T) {
+	const n = 20000
+	t.Run("Object", func(t *testing.T) {
+		var required, data Writer
+		required.ArrStart()
+		data.ObjStart()
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				required.Comma()
+				data.Comma()
+			}
+			key := "k" + strconv.Itoa(i)
+			required.Str(key)
+			data.FieldStart(key)
+			data.Int(i)
+		}
+		required.ArrEnd()
+		data.ObjEnd()
+
+		s, err := CompileSchema([]byte(`{"required":` + required.String() + `,"anyOf":[{"required":["x"]},{"minProperties":1}]}`))
+		require.NoError(t, err)
+		require.NoError(t, s.ValidateBytes(data.Buf))
+	})
+	t.Run("UniqueItems", func(t *testing.T) {
+		s, err := CompileSchema([]byte(`{"uniqueItems":true}`))
+		require.NoError(t, err)
+
+		var data Writer
+		data.ArrStart()
+		for i := 0; i < n; i++ {
+			data.Int(i)
+			data.Comma()
+		}
+		data.RawStr(`1.0]`)
+		var errs SchemaErrors
+		require.ErrorAs(t, s.ValidateBytes(data.Buf), &errs)
+		require.Equal(t, "items 1 and 20000 are equal", errs[0].Message)
+
+		data.Buf = data.Buf[:len(data.Buf)-len(`1.0]`)]
+		data.RawStr(`-1]`)
+		require.NoError(t, s.ValidateBytes(data.Buf))
+	})
+}
+
+func TestSchema_DepthLimit(t *testing.T) {
+	s, err := CompileSchema([]byte(`{"$ref":"#"}`))
+	require.NoError(t, err)
+	err = s.ValidateBytes([]byte(`1`))
+	require.Error(t, err)
+	require.False(t, errors.As(err, new(SchemaErrors)))
+}
+
+func BenchmarkSchema_Validate(b *testing.B) {
+	s, err := CompileSchema([]byte(`{
+		"type":"object",
+		"properties":{"id":{"type":"integer"},"tags":{"type":"array","items":{"type":"string"}}},
+		"required":["id"]
+	}`))
+	require.NoError(b, err)
+	data := []byte(`{"id":1,"tags":["a","b","c"],"extra":{"a":[1,2,3]}}`)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	d := DecodeBytes(data)
+	for i := 0; i < b.N; i++ {
+		d.ResetBytes(data)
+		if err := s.ValidateBuffered(d); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,612 @@
+package jx
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/go-faster/errors"
+)
+
+// schemaMaxDepth limits depth of nested schema applications, preventing
+// infinite recursion of $ref.
+const schemaMaxDepth = 512
+
+// schemaAnn is set of evaluated locations of instance, used by
+// unevaluatedItems and unevaluatedProperties.
+type schemaAnn struct {
+	props    map[string]struct{}
+	items    int // items before this index are evaluated
+	allItems bool
+	itemSet  map[int]struct{}
+}
+
+func (a *schemaAnn) addProp(name string) {
+	if a.props == nil {
+		a.props = map[string]struct{}{}
+	}
+	a.props[name] = struct{}{}
+}
+
+func (a *schemaAnn) addItem(i int) {
+	if a.itemSet == nil {
+		a.itemSet = map[int]struct{}{}
+	}
+	a.itemSet[i] = struct{}{}
+}
+
+func (a *schemaAnn) hasProp(name string) bool {
+	_, ok := a.props[name]
+	return ok
+}
+
+func (a *schemaAnn) hasItem(i int) bool {
+	if a.allItems || i < a.items {
+		return true
+	}
+	_, ok := a.itemSet[i]
+	return ok
+}
+
+// merge adds annotations of b to a.
+func (a *schemaAnn) merge(b *schemaAnn) {
+	if a == nil {
+		return
+	}
+	for name := range b.props {
+		a.addProp(name)
+	}
+	for i := range b.itemSet {
+		a.addItem(i)
+	}
+	if b.items > a.items {
+		a.items = b.items
+	}
+	a.allItems = a.allItems || b.allItems
+}
+
+// schemaValue is instance value. Members of object and elements of array
+// are decoded once and shared by all keywords and applicators.
+type schemaValue struct {
+	raw Raw
+	tt  Type
+
+	decoded  bool
+	err      error
+	members  []rawMember // object
+	index    memberIndex
+	children []*schemaValue // values of members or elements
+}
+
+func newSchemaValue(raw Raw) *schemaValue {
+	return &schemaValue{raw: raw, tt: raw.Type()}
+}
+
+// decode decodes members of object or elements of array, if not yet.
+func (sv *schemaValue) decode() error {
+	if sv.decoded {
+		return sv.err
+	}
+	sv.decoded = true
+	var raws []Raw
+	switch sv.tt {
+	case Object:
+		sv.members, sv.err = rawMembers(DecodeBytes(sv.raw), nil)
+		sv.index = newMemberIndex(sv.members)
+		for _, m := range sv.members {
+			raws = append(raws, m.Value)
+		}
+	case Array:
+		raws, sv.err = rawElems(DecodeBytes(sv.raw), nil)
+	}
+	sv.children = make([]*schemaValue, len(raws))
+	for i, raw := range raws {
+		sv.children[i] = newSchemaValue(raw)
+	}
+	return sv.err
+}
+
+// has reports whether object has member with name.
+func (sv *schemaValue) has(name string) bool {
+	return sv.index.find(name) >= 0
+}
+
+type schemaValidator struct {
+	errs SchemaErrors
+	// err is internal error that stops validation.
+	err error
+
+	path  []string // instance location
+	kw    []string // keyword location
+	depth int
+	// scope is dynamic scope: entered resources with dynamic anchors,
+	// outermost first.
+	scope []*schema
+}
+
+// fail records violation of keyword, returning false.
+func (v *schemaValidator) fail(kw, format string, args ...interface{}) bool {
+	var ptr, kwPtr []byte
+	for _, tok := range v.path {
+		ptr = appendPointerToken(ptr, tok)
+	}
+	for _, tok := range v.kw {
+		kwPtr = appendPointerToken(kwPtr, tok)
+	}
+	if kw != "" {
+		kwPtr = appendPointerToken(kwPtr, kw)
+	}
+	v.errs = append(v.errs, SchemaError{
+		Pointer: string(ptr),
+		Keyword: string(kwPtr),
+		Message: fmt.Sprintf(format, args...),
+	})
+	return false
+}
+
+// internal records internal error, returning false.
+func (v *schemaValidator) internal(err error) bool {
+	if v.err == nil {
+		v.err = err
+	}
+	return false
+}
+
+// apply validates sv against subschema s of keyword.
+func (v *schemaValidator) apply(s *schema, sv *schemaValue, ann *schemaAnn, kw ...string) bool {
+	v.kw = append(v.kw, kw...)
+	ok := v.validate(s, sv, ann)
+	v.kw = v.kw[:len(v.kw)-len(kw)]
+	return ok
+}
+
+// applyAt validates element or member sv against subschema s of keyword.
+func (v *schemaValidator) applyAt(s *schema, sv *schemaValue, elem string, kw ...string) bool {
+	v.path = append(v.path, elem)
+	ok := v.apply(s, sv, nil, kw...)
+	v.path = v.path[:len(v.path)-1]
+	return ok
+}
+
+// test reports whether sv is valid against s, discarding violations.
+func (v *schemaValidator) test(s *schema, sv *schemaValue, ann *schemaAnn, kw ...string) bool {
+	n := len(v.errs)
+	ok := v.apply(s, sv, ann, kw...)
+	v.errs = v.errs[:n]
+	return ok
+}
+
+func (v *schemaValidator) validate(s *schema, sv *schemaValue, ann *schemaAnn) bool {
+	if v.err != nil {
+		return false
+	}
+	if s.isBool {
+		if !s.boolValue {
+			return v.fail("", "false schema")
+		}
+		return true
+	}
+	if v.depth >= schemaMaxDepth {
+		return v.internal(errors.New("schema depth limit exceeded"))
+	}
+	v.depth++
+	defer func() { v.depth-- }()
+
+	if r := s.res; r != nil && r.dynamic != nil {
+		if n := len(v.scope); n == 0 || v.scope[n-1] != r {
+			v.scope = append(v.scope, r)
+			defer func() { v.scope = v.scope[:n] }()
+		}
+	}
+
+	var local schemaAnn
+	ok := true
+	if s.refTo != nil {
+		ok = v.apply(v.refTarget(s), sv, &local, s.refKw) && ok
+	}
+
+	ok = v.validateType(s, sv) && ok
+	switch sv.tt {
+	case Number:
+		ok = v.validateNum(s, sv.raw) && ok
+	case String:
+		ok = v.validateStr(s, sv.raw) && ok
+	case Array:
+		ok = v.validateArr(s, sv, &local) && ok
+	case Object:
+		ok = v.validateObj(s, sv, &local) && ok
+	}
+
+	// In-place applicators.
+	for i, sub := range s.allOf {
+		ok = v.apply(sub, sv, &local, "allOf", strconv.Itoa(i)) && ok
+	}
+	if len(s.anyOf) > 0 {
+		matched := false
+		for i, sub := range s.anyOf {
+			var branch schemaAnn
+			if v.test(sub, sv, &branch, "anyOf", strconv.Itoa(i)) {
+				matched = true
+				local.merge(&branch)
+			}
+		}
+		if !matched {
+			ok = v.fail("anyOf", "value does not match any schema")
+		}
+	}
+	if len(s.oneOf) > 0 {
+		var matched []int
+		for i, sub := range s.oneOf {
+			var branch schemaAnn
+			if v.test(sub, sv, &branch, "oneOf", strconv.Itoa(i)) {
+				matched = append(matched, i)
+				local.merge(&branch)
+			}
+		}
+		switch len(matched) {
+		case 0:
+			ok = v.fail("oneOf", "value does not match any schema")
+		case 1:
+		default:
+			ok = v.fail("oneOf", "value matches schemas %v, expected exactly one", matched)
+		}
+	}
+	if s.not != nil && v.test(s.not, sv, nil, "not") {
+		ok = v.fail("not", "value must not match schema")
+	}
+	if s.if_ != nil {
+		var cond schemaAnn
+		if v.test(s.if_, sv, &cond, "if") {
+			local.merge(&cond)
+			if s.then != nil {
+				ok = v.apply(s.then, sv, &local, "then") && ok
+			}
+		} else if s.else_ != nil {
+			ok = v.apply(s.else_, sv, &local, "else") && ok
+		}
+	}
+
+	// Unevaluated locations, after all other applicators.
+	switch sv.tt {
+	case Array:
+		if s.unevaluatedItems != nil {
+			ok = v.validateUnevaluatedItems(s, sv, &local) && ok
+		}
+	case Object:
+		if s.unevaluatedProperties != nil {
+			ok = v.validateUnevaluatedProps(s, sv, &local) && ok
+		}
+	}
+
+	if ok {
+		ann.merge(&local)
+	}
+	return ok && v.err == nil
+}
+
+// refTarget returns schema referenced by s, resolving $dynamicRef to
+// outermost dynamic anchor in dynamic scope.
+func (v *schemaValidator) refTarget(s *schema) *schema {
+	if s.dynamicRef == "" {
+		return s.refTo
+	}
+	for _, r := range v.scope {
+		if to, ok := r.dynamic[s.dynamicRef]; ok {
+			return to
+		}
+	}
+	return s.refTo
+}
+
+func (v *schemaValidator) validateType(s *schema, sv *schemaValue) bool {
+	raw, tt := sv.raw, sv.tt
+	if s.types != 0 {
+		var t schemaType
+		switch tt {
+		case Null:
+			t = schemaNull
+		case Bool:
+			t = schemaBool
+		case Object:
+			t = schemaObject
+		case Array:
+			t = schemaArray
+		case String:
+			t = schemaString
+		case Number:
+			t = schemaNumber
+			if s.types&schemaInteger != 0 {
+				n, err := DecodeBytes(raw).Num()
+				if err != nil {
+					return v.internal(err)
+				}
+				if schemaIsInt(n) {
+					t |= schemaInteger
+				}
+			}
+		}
+		if s.types&t == 0 {
+			return v.fail("type", "expected %s, got %s", s.types, tt)
+		}
+	}
+
+	ok := true
+	if len(s.enum) > 0 {
+		found := false
+		for _, e := range s.enum {
+			eq, err := rawEqual(raw, e)
+			if err != nil {
+				return v.internal(err)
+			}
+			if eq {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ok = v.fail("enum", "value is not one of enumerated values")
+		}
+	}
+	if s.hasConst {
+		eq, err := rawEqual(raw, s.constVal)
+		if err != nil {
+			return v.internal(err)
+		}
+		if !eq {
+			ok = v.fail("const", "value must be %s", s.constVal)
+		}
+	}
+	return ok
+}
+
+// schemaIsInt reports whether number value is integer, like 1 or 1.0.
+func schemaIsInt(n Num) bool {
+	if r, ok := numRat(n); ok {
+		return r.IsInt()
+	}
+	// Exponent is too big, so only integers are possible.
+	return !bytes.Contains(n, []byte("e-")) && !bytes.Contains(n, []byte("E-"))
+}
+
+func (v *schemaValidator) validateNum(s *schema, raw Raw) bool {
+	n, err := DecodeBytes(raw).Num()
+	if err != nil {
+		return v.internal(err)
+	}
+	ok := true
+	for _, c := range []struct {
+		kw     string
+		limit  schemaNum
+		failed func(c int) bool
+		rel    string
+	}{
+		{"maximum", s.maximum, func(c int) bool { return c > 0 }, "less than or equal to"},
+		{"exclusiveMaximum", s.exclusiveMaximum, func(c int) bool { return c >= 0 }, "less than"},
+		{"minimum", s.minimum, func(c int) bool { return c < 0 }, "greater than or equal to"},
+		{"exclusiveMinimum", s.exclusiveMinimum, func(c int) bool { return c <= 0 }, "greater than"},
+	} {
+		if !c.limit.set {
+			continue
+		}
+		cmp, err := numCmp(n, c.limit.raw)
+		if err != nil {
+			return v.internal(err)
+		}
+		if c.failed(cmp) {
+			ok = v.fail(c.kw, "%s must be %s %s", n, c.rel, c.limit.raw)
+		}
+	}
+	if s.multipleOf != nil {
+		r, exact := numRat(n)
+		switch {
+		case !exact:
+			ok = v.fail("multipleOf", "%s is out of range", n)
+		case !new(big.Rat).Quo(r, s.multipleOf).IsInt():
+			ok = v.fail("multipleOf", "%s must be multiple of %s", n, s.multipleOf.RatString())
+		}
+	}
+	return ok
+}
+
+func (v *schemaValidator) validateStr(s *schema, raw Raw) bool {
+	if s.maxLength < 0 && s.minLength < 0 && s.pattern == nil {
+		return true
+	}
+	str, err := DecodeBytes(raw).StrBytes()
+	if err != nil {
+		return v.internal(err)
+	}
+	ok := true
+	length := utf8.RuneCount(str)
+	if s.maxLength >= 0 && length > s.maxLength {
+		ok = v.fail("maxLength", "length %d must be less than or equal to %d", length, s.maxLength)
+	}
+	if s.minLength >= 0 && length < s.minLength {
+		ok = v.fail("minLength", "length %d must be greater than or equal to %d", length, s.minLength)
+	}
+	if s.pattern != nil && !s.pattern.Match(str) {
+		ok = v.fail("pattern", "%q does not match %q", str, s.pattern)
+	}
+	return ok
+}
+
+func (v *schemaValidator) validateArr(s *schema, sv *schemaValue, ann *schemaAnn) bool {
+	if err := sv.decode(); err != nil {
+		return v.internal(err)
+	}
+	elems := sv.children
+	ok := true
+	if s.maxItems >= 0 && len(elems) > s.maxItems {
+		ok = v.fail("maxItems", "%d items must be at most %d", len(elems), s.maxItems)
+	}
+	if s.minItems >= 0 && len(elems) < s.minItems {
+		ok = v.fail("minItems", "%d items must be at least %d", len(elems), s.minItems)
+	}
+	if s.uniqueItems {
+		ok = v.validateUnique(elems) && ok
+	}
+
+	for i, e := range elems {
+		idx := strconv.Itoa(i)
+		switch {
+		case i < len(s.prefixItems):
+			ok = v.applyAt(s.prefixItems[i], e, idx, "prefixItems", idx) && ok
+		case s.items != nil:
+			ok = v.applyAt(s.items, e, idx, "items") && ok
+		}
+	}
+	if n := len(s.prefixItems); n > ann.items {
+		ann.items = n
+	}
+	if s.items != nil {
+		ann.allItems = true
+	}
+
+	if s.contains != nil {
+		matched := 0
+		for i, e := range elems {
+			n := len(v.errs)
+			if v.applyAt(s.contains, e, strconv.Itoa(i), "contains") {
+				matched++
+				ann.addItem(i)
+			}
+			v.errs = v.errs[:n]
+		}
+		minContains := s.minContains
+		if minContains < 0 {
+			minContains = 1
+		}
+		if matched < minContains {
+			kw := "contains"
+			if s.minContains >= 0 {
+				kw = "minContains"
+			}
+			ok = v.fail(kw, "%d items match contains schema, expected at least %d", matched, minContains)
+		}
+		if s.maxContains >= 0 && matched > s.maxContains {
+			ok = v.fail("maxContains", "%d items match contains schema, expected at most %d", matched, s.maxContains)
+		}
+	}
+	return ok
+}
+
+// validateUnique checks that elements are unique, comparing only elements
+// with equal hash.
+func (v *schemaValidator) validateUnique(elems []*schemaValue) bool {
+	seen := make(map[uint64][]int, len(elems))
+	for i, e := range elems {
+		h, err := rawHash(e.raw)
+		if err != nil {
+			return v.internal(err)
+		}
+		for _, j := range seen[h] {
+			eq, err := rawEqual(elems[j].raw, e.raw)
+			if err != nil {
+				return v.internal(err)
+			}
+			if eq {
+				return v.fail("uniqueItems", "items %d and %d are equal", j, i)
+			}
+		}
+		seen[h] = append(seen[h], i)
+	}
+	return true
+}
+
+func (v *schemaValidator) validateUnevaluatedItems(s *schema, sv *schemaValue, ann *schemaAnn) bool {
+	if err := sv.decode(); err != nil {
+		return v.internal(err)
+	}
+	ok := true
+	for i, e := range sv.children {
+		if ann.hasItem(i) {
+			continue
+		}
+		ok = v.applyAt(s.unevaluatedItems, e, strconv.Itoa(i), "unevaluatedItems") && ok
+	}
+	ann.allItems = true
+	return ok
+}
+
+func (v *schemaValidator) validateObj(s *schema, sv *schemaValue, ann *schemaAnn) bool {
+	if err := sv.decode(); err != nil {
+		return v.internal(err)
+	}
+	members, has := sv.members, sv.has
+
+	ok := true
+	if s.maxProperties >= 0 && len(members) > s.maxProperties {
+		ok = v.fail("maxProperties", "%d properties must be at most %d", len(members), s.maxProperties)
+	}
+	if s.minProperties >= 0 && len(members) < s.minProperties {
+		ok = v.fail("minProperties", "%d properties must be at least %d", len(members), s.minProperties)
+	}
+	for _, name := range s.required {
+		if !has(name) {
+			ok = v.fail("required", "property %q is required", name)
+		}
+	}
+	for _, dep := range s.dependentRequired {
+		if !has(dep.name) {
+			continue
+		}
+		for _, name := range dep.required {
+			if !has(name) {
+				ok = v.fail("dependentRequired", "property %q is required by %q", name, dep.name)
+			}
+		}
+	}
+
+	for i, m := range members {
+		value := sv.children[i]
+		evaluated := false
+		for _, p := range s.properties {
+			if p.name == m.Key {
+				evaluated = true
+				ok = v.applyAt(p.schema, value, m.Key, "properties", m.Key) && ok
+			}
+		}
+		for _, p := range s.patternProperties {
+			if p.re.MatchString(m.Key) {
+				evaluated = true
+				ok = v.applyAt(p.schema, value, m.Key, "patternProperties", p.re.String()) && ok
+			}
+		}
+		if !evaluated && s.additionalProperties != nil {
+			evaluated = true
+			ok = v.applyAt(s.additionalProperties, value, m.Key, "additionalProperties") && ok
+		}
+		if evaluated {
+			ann.addProp(m.Key)
+		}
+		if s.propertyNames != nil {
+			var w Writer
+			w.Str(m.Key)
+			ok = v.applyAt(s.propertyNames, newSchemaValue(w.Buf), m.Key, "propertyNames") && ok
+		}
+	}
+
+	for _, dep := range s.dependentSchemas {
+		if has(dep.name) {
+			ok = v.apply(dep.schema, sv, ann, "dependentSchemas", dep.name) && ok
+		}
+	}
+	return ok
+}
+
+func (v *schemaValidator) validateUnevaluatedProps(s *schema, sv *schemaValue, ann *schemaAnn) bool {
+	if err := sv.decode(); err != nil {
+		return v.internal(err)
+	}
+	ok := true
+	for i, m := range sv.members {
+		if ann.hasProp(m.Key) {
+			continue
+		}
+		ok = v.applyAt(s.unevaluatedProperties, sv.children[i], m.Key, "unevaluatedProperties") && ok
+		ann.addProp(m.Key)
+	}
+	return ok
+}
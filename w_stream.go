@@ -1,6 +1,7 @@
 package jx
 
 import (
+	"context"
 	"errors"
 	"io"
 
@@ -20,11 +21,39 @@ func (w *Writer) Close() error {
 	return nil
 }
 
+// Err returns write error of streaming mode, if any.
+//
+// Error is sticky: once write failed, all subsequent writes fail.
+func (w *Writer) Err() error {
+	return w.stream.err()
+}
+
+// SetContext sets context of streaming mode. If context is done, next
+// flush fails with context error.
+//
+// Does nothing if w is not in streaming mode. Context is kept on ResetWriter.
+func (w *Writer) SetContext(ctx context.Context) {
+	w.stream.setContext(ctx)
+}
+
+// SetFlushWatermark sets size of buffered data that triggers flush in
+// streaming mode, so data is written before buffer is full. Zero or
+// negative n means flushing only full buffer, which is default.
+//
+// Does nothing if w is not in streaming mode. Watermark is kept on
+// ResetWriter.
+func (w *Writer) SetFlushWatermark(n int) {
+	w.stream.setWatermark(n)
+}
+
 var errStreaming = errors.New("unexpected call in streaming mode")
 
 type streamState struct {
 	writer   io.Writer
 	writeErr error
+
+	ctx       context.Context
+	watermark int
 }
 
 func newStreamState(w io.Writer) *streamState {
@@ -52,10 +81,37 @@ func (s *streamState) fail() bool {
 	return s.writeErr != nil
 }
 
+func (s *streamState) err() error {
+	if s == nil {
+		return nil
+	}
+	return s.writeErr
+}
+
+func (s *streamState) setContext(ctx context.Context) {
+	if s == nil {
+		return
+	}
+	s.ctx = ctx
+}
+
+func (s *streamState) setWatermark(n int) {
+	if s == nil {
+		return
+	}
+	s.watermark = n
+}
+
 func (s *streamState) flush(buf []byte) ([]byte, bool) {
 	if s.fail() {
 		return nil, true
 	}
+	if s.ctx != nil {
+		if err := s.ctx.Err(); err != nil {
+			s.setError(err)
+			return nil, true
+		}
+	}
 
 	n, err := s.writer.Write(buf)
 	switch {
@@ -99,5 +155,10 @@ func writeStreamByteseqSlow[S byteseq.Byteseq](w *Writer, s S) bool {
 		w.Buf = w.Buf[:len(w.Buf)+n]
 	}
 	w.Buf = append(w.Buf, s...)
+	if wm := w.stream.watermark; wm > 0 && len(w.Buf) >= wm {
+		var fail bool
+		w.Buf, fail = w.stream.flush(w.Buf)
+		return fail
+	}
 	return false
 }